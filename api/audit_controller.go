@@ -0,0 +1,96 @@
+// api/audit_controller.go
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct{}
+
+// GetAuditLogs 查询审计日志
+// 筛选: entity, entity_id, action, actor_id, request_id, transfer_id, from / to (YYYY-MM-DD 或 RFC3339，to 为日期时包含当天)
+func (ac *AuditController) GetAuditLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	db := database.GetDB()
+	query := db.Model(&models.AuditLog{})
+
+	if entity := c.Query("entity"); entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+	if entityID := c.Query("entity_id"); entityID != "" {
+		query = query.Where("entity_id = ?", entityID)
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if actorID := c.Query("actor_id"); actorID != "" {
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if requestID := c.Query("request_id"); requestID != "" {
+		query = query.Where("request_id = ?", requestID)
+	}
+	if transferID := c.Query("transfer_id"); transferID != "" {
+		query = query.Where("transfer_id = ?", transferID)
+	}
+	if from := c.Query("from"); from != "" {
+		t, _, err := parseTimeParam(from)
+		if err != nil {
+			errorResponse(c, 400, "from 格式错误，应为 YYYY-MM-DD 或 RFC3339")
+			return
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, dateOnly, err := parseTimeParam(to)
+		if err != nil {
+			errorResponse(c, 400, "to 格式错误，应为 YYYY-MM-DD 或 RFC3339")
+			return
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+			query = query.Where("created_at < ?", t)
+		} else {
+			query = query.Where("created_at <= ?", t)
+		}
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var logs []models.AuditLog
+	if err := query.Order("created_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		errorResponse(c, 500, "查询审计日志失败: "+err.Error())
+		return
+	}
+
+	success(c, PaginatedResponse{
+		Items:    logs,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// parseTimeParam 解析日期或 RFC3339 时间，dateOnly 表示只给了日期
+func parseTimeParam(s string) (t time.Time, dateOnly bool, err error) {
+	if len(s) == len(utils.DateLayout) {
+		t, err = utils.ParseDate(s)
+		return t, true, err
+	}
+	t, err = time.Parse(time.RFC3339, s)
+	return t, false, err
+}
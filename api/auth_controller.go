@@ -4,16 +4,18 @@ package api
 import (
 	"time"
 
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type AuthController struct{}
 
-// Register 用户注册（开发测试用）
+// Register 用户注册（由管理员创建账号并分配角色）
 func (ac *AuthController) Register(c *gin.Context) {
 	var req models.RegisterRequest
 
@@ -22,7 +24,15 @@ func (ac *AuthController) Register(c *gin.Context) {
 		return
 	}
 
-	db := database.GetDB()
+	if req.Role == 0 {
+		req.Role = models.RoleViewer
+	}
+	if !models.IsValidRole(req.Role) {
+		errorResponse(c, 400, "无效的角色")
+		return
+	}
+
+	db := requestDB(c)
 	var count int64
 	db.Model(&models.User{}).Where("username = ?", req.Username).Count(&count)
 	if count > 0 {
@@ -30,6 +40,15 @@ func (ac *AuthController) Register(c *gin.Context) {
 		return
 	}
 
+	var employeeID *uint
+	if req.EmployeeID != 0 {
+		if msg := checkEmployeeLink(db, req.EmployeeID, 0); msg != "" {
+			errorResponse(c, 400, msg)
+			return
+		}
+		employeeID = &req.EmployeeID
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		errorResponse(c, 500, "密码加密失败")
@@ -37,16 +56,23 @@ func (ac *AuthController) Register(c *gin.Context) {
 	}
 
 	user := models.User{
-		Username:  req.Username,
-		Password:  string(hashedPassword),
-		RealName:  req.RealName,
-		Email:     req.Email,
-		Phone:     req.Phone,
-		LastLogin: time.Now(),
+		Username:   req.Username,
+		Password:   string(hashedPassword),
+		Role:       req.Role,
+		RealName:   req.RealName,
+		Email:      req.Email,
+		Phone:      req.Phone,
+		EmployeeID: employeeID,
+		LastLogin:  time.Now(),
 	}
 
-	result := db.Create(&user)
-	if result.Error != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return audit.Created(tx, models.AuditEntityUser, user.ID, user)
+	})
+	if err != nil {
 		errorResponse(c, 500, "注册失败")
 		return
 	}
@@ -97,8 +123,9 @@ func (ac *AuthController) Login(c *gin.Context) {
 	user.Password = ""
 
 	success(c, models.LoginResponse{
-		Token: token,
-		User:  user,
+		Token:       token,
+		User:        user,
+		Permissions: models.GetPermissions(user.Role),
 	})
 }
 
@@ -125,3 +152,34 @@ func (ac *AuthController) GetProfile(c *gin.Context) {
 
 	success(c, user)
 }
+
+// currentUserID 获取 JWTAuth 中间件写入的当前登录用户ID
+func currentUserID(c *gin.Context) (uint, bool) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		return 0, false
+	}
+	userID, ok := value.(uint)
+	if !ok || userID == 0 {
+		return 0, false
+	}
+	return userID, true
+}
+
+// requestDB 绑定当前请求上下文的数据库连接，审计日志从中获取操作者和请求ID
+func requestDB(c *gin.Context) *gorm.DB {
+	return database.GetDB().WithContext(c.Request.Context())
+}
+
+// currentUser 获取当前登录用户的完整信息
+func currentUser(c *gin.Context) (*models.User, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+	var user models.User
+	if err := database.GetDB().First(&user, userID).Error; err != nil {
+		return nil, false
+	}
+	return &user, true
+}
@@ -3,35 +3,59 @@ package api
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"strconv"
 	"time"
 
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/backup"
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/service"
 	"github.com/gin-gonic/gin"
 )
 
-type BackupController struct{}
+// BackupController 备份与恢复，Dir / Keep 为备份目录及其保留策略 (backup.dir / backup.keep)，
+// Encryption 为备份和员工导出的加密方式 (backup.encryption)，MaxRestoreSize 为上传恢复文件的大小上限 (字节)
+type BackupController struct {
+	Dir            string
+	Keep           backup.Retention
+	Encryption     backup.Encryption
+	MaxRestoreSize int64
+}
 
-// ExportEmployees 导出员工信息为CSV
+// ExportEmployees 导出员工信息为CSV，配置了备份加密时导出加密的 .csv.age 文件
 func (bc *BackupController) ExportEmployees(c *gin.Context) {
 	db := database.GetDB()
 	var employees []models.Employee
-	if err := db.Find(&employees).Error; err != nil {
+	if err := db.Preload("Department").Find(&employees).Error; err != nil {
 		errorResponse(c, 500, "获取数据失败")
 		return
 	}
 
 	// 设置响应头，告诉浏览器这是一个下载文件
-	fileName := fmt.Sprintf("employees_backup_%s.csv", time.Now().Format("20060102150405"))
-	c.Header("Content-Type", "text/csv; charset=utf-8")
+	fileName := bc.Encryption.FileName(fmt.Sprintf("employees_backup_%s.csv", time.Now().Format("20060102150405")))
+	if bc.Encryption.Enabled() {
+		c.Header("Content-Type", "application/octet-stream")
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+	}
 	c.Header("Content-Disposition", "attachment; filename="+fileName)
 
+	// 员工信息含电话、邮箱等个人信息，配置了加密时整个文件加密
+	out, err := bc.Encryption.EncryptWriter(c.Writer)
+	if err != nil {
+		errorResponse(c, 500, "加密失败: "+err.Error())
+		return
+	}
+
 	// 创建 CSV Writer
-	writer := csv.NewWriter(c.Writer)
+	writer := csv.NewWriter(out)
 
 	// 写入 UTF-8 BOM 防止 Excel 打开乱码
-	c.Writer.Write([]byte("\xEF\xBB\xBF"))
+	out.Write([]byte("\xEF\xBB\xBF"))
 
 	// 写入表头
 	header := []string{"ID", "员工编号", "姓名", "状态", "部门", "职位", "入职日期", "电话", "邮箱"}
@@ -39,12 +63,16 @@ func (bc *BackupController) ExportEmployees(c *gin.Context) {
 
 	// 写入数据行
 	for _, emp := range employees {
+		deptName := ""
+		if emp.Department != nil {
+			deptName = emp.Department.Name
+		}
 		row := []string{
 			fmt.Sprintf("%d", emp.ID),
 			emp.EmployeeID,
 			emp.Name,
 			models.GetStatusText(emp.Status),
-			emp.Department,
+			deptName,
 			emp.Position,
 			emp.ArrivalDate,
 			emp.Phone,
@@ -54,4 +82,140 @@ func (bc *BackupController) ExportEmployees(c *gin.Context) {
 	}
 
 	writer.Flush()
+	out.Close()
+}
+
+// CreateBackup 下载全部数据的完整备份 (tar.gz，每张表一个 JSON Lines 文件，附清单和校验和)，
+// 配置了备份加密时为加密的 .tar.gz.age 文件
+// @Summary 完整备份
+// @Tags 系统维护
+// @Produce application/gzip
+// @Router /api/backup/full [get]
+func (bc *BackupController) CreateBackup(c *gin.Context) {
+	// 先写入临时文件，备份失败时还能返回错误信息
+	file, err := os.CreateTemp("", "hrms-backup-*.tar.gz")
+	if err != nil {
+		errorResponse(c, 500, "创建备份失败")
+		return
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	manifest, err := backup.Create(requestDB(c), file, bc.Encryption)
+	if err != nil {
+		errorResponse(c, 500, "创建备份失败: "+err.Error())
+		return
+	}
+
+	fileName := bc.Encryption.FileName(fmt.Sprintf("hrms_backup_%s.tar.gz", manifest.CreatedAt.Format("20060102150405")))
+	c.FileAttachment(file.Name(), fileName)
+}
+
+// RestoreBackup 从完整备份恢复数据库
+// @Summary 恢复备份
+// @Description 校验备份包后在一个事务中载入全部数据；默认要求数据库为空，replace=true 时覆盖现有数据
+// @Tags 系统维护
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "备份文件 (.tar.gz 或加密的 .tar.gz.age)"
+// @Param passphrase formData string false "加密备份的口令，不填时使用服务器配置的口令或私钥"
+// @Param replace query bool false "清空现有数据后恢复"
+// @Param dry_run query bool false "仅校验备份文件，不写入数据库"
+// @Success 200 {object} Response{data=backup.Manifest}
+// @Router /api/backup/restore [post]
+func (bc *BackupController) RestoreBackup(c *gin.Context) {
+	// 限制请求体大小，避免超大上传占满内存或临时目录；留出 1MB 给表单的其他字段
+	tooLarge := fmt.Sprintf("备份文件不能超过 %dMB，更大的备份请使用命令行 backup restore", bc.MaxRestoreSize>>20)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, bc.MaxRestoreSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		errorResponse(c, 400, tooLarge)
+		return
+	}
+	if err != nil {
+		errorResponse(c, 400, "请上传备份文件 (表单字段 file)")
+		return
+	}
+	if fileHeader.Size > bc.MaxRestoreSize {
+		errorResponse(c, 400, tooLarge)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		errorResponse(c, 400, "读取文件失败")
+		return
+	}
+	defer file.Close()
+
+	enc := bc.Encryption
+	if passphrase := c.PostForm("passphrase"); passphrase != "" {
+		enc = backup.Encryption{Passphrase: passphrase}
+	}
+
+	var manifest *backup.Manifest
+	if c.Query("dry_run") == "true" {
+		manifest, err = backup.Verify(requestDB(c), file, enc)
+	} else {
+		manifest, err = backup.Restore(requestDB(c), file, c.Query("replace") == "true", enc)
+	}
+
+	var archiveErr *backup.ArchiveError
+	switch {
+	case errors.As(err, &archiveErr), errors.Is(err, backup.ErrDatabaseNotEmpty):
+		errorResponse(c, 400, err.Error())
+	case err != nil:
+		errorResponse(c, 500, "恢复失败: "+err.Error())
+	default:
+		success(c, manifest)
+	}
+}
+
+// GetBackupHistory 查询写入备份目录的备份记录 (定时备份与手动备份)，按开始时间倒序
+// 筛选: status (success / failed)
+func (bc *BackupController) GetBackupHistory(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := requestDB(c).Model(&models.BackupRun{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var runs []models.BackupRun
+	if err := query.Order("started_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&runs).Error; err != nil {
+		errorResponse(c, 500, "查询备份记录失败: "+err.Error())
+		return
+	}
+
+	success(c, PaginatedResponse{
+		Items:    runs,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// RunBackup 立即执行一次备份写入备份目录，并按保留策略清理旧备份
+func (bc *BackupController) RunBackup(c *gin.Context) {
+	result, err := service.RunBackup(requestDB(c), bc.Dir, models.BackupTriggerManual, bc.Keep, bc.Encryption)
+	if err != nil {
+		c.JSON(http.StatusOK, Response{
+			Code:    500,
+			Message: err.Error(),
+			Data:    result,
+		})
+		return
+	}
+	success(c, result)
 }
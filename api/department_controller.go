@@ -2,23 +2,75 @@
 package api
 
 import (
+	"errors"
+	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/service"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type DepartmentController struct{}
 
-// GetDepartments 获取所有部门
+// GetDepartments 获取所有部门，deleted 参数可包含或只列出已删除的部门
 func (dc *DepartmentController) GetDepartments(c *gin.Context) {
-	db := database.GetDB()
+	query, ok := filterDeleted(database.GetDB().Model(&models.Department{}), c.Query("deleted"))
+	if !ok {
+		errorResponse(c, 400, "deleted 参数无效，可选 exclude、include、only")
+		return
+	}
 	var depts []models.Department
-	db.Find(&depts)
+	query.Find(&depts)
 	success(c, depts)
 }
 
+// GetHeadcount 统计某一日期各部门的在岗人数，as_of 默认为今天
+func (dc *DepartmentController) GetHeadcount(c *gin.Context) {
+	asOf := c.DefaultQuery("as_of", utils.Today())
+	date, err := utils.ParseDate(asOf)
+	if err != nil {
+		errorResponse(c, 400, "as_of 格式错误，应为 YYYY-MM-DD")
+		return
+	}
+
+	report, err := service.HeadcountAsOf(database.GetDB(), date)
+	if err != nil {
+		errorResponse(c, 500, err.Error())
+		return
+	}
+	success(c, report)
+}
+
+// GetDepartmentTree 获取部门树及各级在岗人数，root_id 指定时只返回该部门及其下级部门
+func (dc *DepartmentController) GetDepartmentTree(c *gin.Context) {
+	var rootID uint64
+	if v := c.Query("root_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			errorResponse(c, 400, "root_id 无效")
+			return
+		}
+		rootID = id
+	}
+
+	tree, err := service.DepartmentTree(requestDB(c), uint(rootID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errorResponse(c, 404, "部门不存在")
+			return
+		}
+		errorResponse(c, 500, err.Error())
+		return
+	}
+	success(c, tree)
+}
+
 // CreateDepartment 创建部门
 func (dc *DepartmentController) CreateDepartment(c *gin.Context) {
 	var req models.CreateDepartmentRequest
@@ -27,21 +79,45 @@ func (dc *DepartmentController) CreateDepartment(c *gin.Context) {
 		return
 	}
 
+	// 已删除的部门仍占用部门编号
+	db := requestDB(c)
+	var existing models.Department
+	if err := db.Unscoped().Where("dept_no = ?", req.DeptNo).First(&existing).Error; err == nil {
+		if existing.DeletedAt.Valid {
+			errorResponse(c, 400, "部门编号已被已删除的部门使用，如需恢复请使用恢复功能")
+			return
+		}
+		errorResponse(c, 400, "部门编号已存在")
+		return
+	}
+
 	dept := models.Department{
 		DeptNo:    req.DeptNo,
 		Name:      req.Name,
 		ManagerID: req.ManagerID,
 	}
+	if req.ParentID != 0 {
+		if err := service.CheckDepartmentParent(db, 0, req.ParentID); err != nil {
+			hierarchyError(c, err)
+			return
+		}
+		dept.ParentID = &req.ParentID
+	}
 
-	db := database.GetDB()
-	if err := db.Create(&dept).Error; err != nil {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&dept).Error; err != nil {
+			return err
+		}
+		return audit.Created(tx, models.AuditEntityDepartment, dept.ID, dept)
+	})
+	if err != nil {
 		errorResponse(c, 500, "创建部门失败")
 		return
 	}
 	success(c, dept)
 }
 
-// UpdateDepartment 更新部门
+// UpdateDepartment 更新部门，支持 If-Match 指定读取时的版本号
 func (dc *DepartmentController) UpdateDepartment(c *gin.Context) {
 	id := c.Param("id")
 	var req models.UpdateDepartmentRequest
@@ -49,27 +125,203 @@ func (dc *DepartmentController) UpdateDepartment(c *gin.Context) {
 		errorResponse(c, 400, "参数错误")
 		return
 	}
+	expected, err := ifMatchVersion(c)
+	if err != nil {
+		errorResponse(c, 400, err.Error())
+		return
+	}
 
-	db := database.GetDB()
+	db := requestDB(c)
 	var dept models.Department
 	if err := db.First(&dept, id).Error; err != nil {
 		errorResponse(c, 404, "部门不存在")
 		return
 	}
+	if err := checkVersion(expected, dept.Version); err != nil {
+		versionConflict(c, err, &models.Department{}, dept.ID)
+		return
+	}
 
+	before := dept
 	dept.DeptNo = req.DeptNo
 	dept.Name = req.Name
 	dept.ManagerID = req.ManagerID
 
-	if err := db.Save(&dept).Error; err != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := service.UpdateVersioned(tx, &models.Department{}, dept.ID, dept.Version, map[string]interface{}{
+			"dept_no":    dept.DeptNo,
+			"name":       dept.Name,
+			"manager_id": dept.ManagerID,
+		}); err != nil {
+			return err
+		}
+		if err := tx.First(&dept, dept.ID).Error; err != nil {
+			return err
+		}
+		return audit.Updated(tx, models.AuditEntityDepartment, dept.ID, before, dept)
+	})
+	if errors.Is(err, service.ErrVersionConflict) {
+		versionConflict(c, err, &models.Department{}, dept.ID)
+		return
+	}
+	if err != nil {
 		errorResponse(c, 500, "更新部门失败")
 		return
 	}
 
+	setETag(c, dept.Version)
 	success(c, dept)
 }
 
-// DeleteDepartment 删除部门
+// MoveDepartment 把部门 (连同其下级部门) 移到新的上级部门下，parent_id 为 0 表示移为顶级部门
+func (dc *DepartmentController) MoveDepartment(c *gin.Context) {
+	deptID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errorResponse(c, 400, "无效的部门ID")
+		return
+	}
+	var req models.MoveDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, 400, "参数错误")
+		return
+	}
+
+	expected, err := ifMatchVersion(c)
+	if err != nil {
+		errorResponse(c, 400, err.Error())
+		return
+	}
+
+	db := requestDB(c)
+	var dept models.Department
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&dept, deptID).Error; err != nil {
+			return err
+		}
+		if err := checkVersion(expected, dept.Version); err != nil {
+			return err
+		}
+		if req.ParentID != 0 {
+			if err := service.CheckDepartmentParent(tx, dept.ID, req.ParentID); err != nil {
+				return err
+			}
+		}
+
+		before := dept
+		dept.ParentID = nil
+		if req.ParentID != 0 {
+			dept.ParentID = &req.ParentID
+		}
+		if err := service.UpdateVersioned(tx, &models.Department{}, dept.ID, dept.Version, map[string]interface{}{"parent_id": dept.ParentID}); err != nil {
+			return err
+		}
+		if err := tx.First(&dept, dept.ID).Error; err != nil {
+			return err
+		}
+		return audit.Updated(tx, models.AuditEntityDepartment, dept.ID, before, dept)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			errorResponse(c, 404, "部门不存在")
+		case errors.Is(err, service.ErrVersionConflict):
+			versionConflict(c, err, &models.Department{}, dept.ID)
+		default:
+			hierarchyError(c, err)
+		}
+		return
+	}
+	setETag(c, dept.Version)
+	success(c, dept)
+}
+
+// MergeDepartment 把部门合并到另一个部门：员工批量调入、下级部门改挂，原部门归档
+func (dc *DepartmentController) MergeDepartment(c *gin.Context) {
+	deptID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errorResponse(c, 400, "无效的部门ID")
+		return
+	}
+	var req models.MergeDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, 400, "参数错误: "+err.Error())
+		return
+	}
+	actorID, ok := currentUserID(c)
+	if !ok {
+		errorResponse(c, 401, "未认证")
+		return
+	}
+
+	var result *service.ReorgResult
+	err = requestDB(c).Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = service.MergeDepartment(tx, uint(deptID), req.TargetDeptID, actorID, req.Reason, time.Now())
+		return err
+	})
+	if err != nil {
+		reorgError(c, "部门合并失败", err)
+		return
+	}
+	success(c, result)
+}
+
+// SplitDepartment 按员工名单拆分部门到已有或新建的部门，可选归档原部门
+func (dc *DepartmentController) SplitDepartment(c *gin.Context) {
+	deptID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errorResponse(c, 400, "无效的部门ID")
+		return
+	}
+	var req models.SplitDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, 400, "参数错误: "+err.Error())
+		return
+	}
+	actorID, ok := currentUserID(c)
+	if !ok {
+		errorResponse(c, 401, "未认证")
+		return
+	}
+
+	var result *service.ReorgResult
+	err = requestDB(c).Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = service.SplitDepartment(tx, uint(deptID), req, actorID, time.Now())
+		return err
+	})
+	if err != nil {
+		reorgError(c, "部门拆分失败", err)
+		return
+	}
+	success(c, result)
+}
+
+// reorgError 请求不合法时返回 400，与并发的修改冲突时返回 409，其他错误返回 500
+func reorgError(c *gin.Context, action string, err error) {
+	var re *service.ReorgError
+	if errors.As(err, &re) {
+		errorResponse(c, 400, re.Message)
+		return
+	}
+	if errors.Is(err, service.ErrVersionConflict) || errors.Is(err, service.ErrStatusChanged) {
+		conflictResponse(c, http.StatusConflict, err.Error(), nil, 0)
+		return
+	}
+	errorResponse(c, 500, action+": "+err.Error())
+}
+
+// hierarchyError 上级部门不合法时返回 400，其他错误返回 500
+func hierarchyError(c *gin.Context, err error) {
+	var he *service.DepartmentHierarchyError
+	if errors.As(err, &he) {
+		errorResponse(c, 400, he.Message)
+		return
+	}
+	errorResponse(c, 500, "设置上级部门失败")
+}
+
+// DeleteDepartment 删除部门 (软删除，可恢复)
 func (dc *DepartmentController) DeleteDepartment(c *gin.Context) {
 	id := c.Param("id")
 	deptID, err := strconv.ParseUint(id, 10, 32)
@@ -78,23 +330,107 @@ func (dc *DepartmentController) DeleteDepartment(c *gin.Context) {
 		return
 	}
 
-	db := database.GetDB()
+	db := requestDB(c)
 	var dept models.Department
 	if err := db.First(&dept, deptID).Error; err != nil {
 		errorResponse(c, 404, "部门不存在")
 		return
 	}
 
+	// 已结束的调动记录保留原部门，只有尚未生效的调动需要先处理
 	var transferCount int64
-	db.Model(&models.Transfer{}).Where("from_dept_id = ? OR to_dept_id = ?", uint(deptID), uint(deptID)).Count(&transferCount)
+	db.Model(&models.Transfer{}).
+		Where("(from_dept_id = ? OR to_dept_id = ?) AND status IN ?", uint(deptID), uint(deptID),
+			[]int{models.TransferStatusPending, models.TransferStatusApproved}).
+		Count(&transferCount)
 	if transferCount > 0 {
-		errorResponse(c, 400, "该部门存在关联的调动记录，无法删除")
+		errorResponse(c, 400, "该部门存在未生效的调动申请，无法删除")
+		return
+	}
+
+	var employeeCount int64
+	db.Model(&models.Employee{}).Where("department_id = ?", uint(deptID)).Count(&employeeCount)
+	if employeeCount > 0 {
+		errorResponse(c, 400, "该部门下仍有员工，无法删除")
 		return
 	}
 
-	if err := db.Delete(&models.Department{}, deptID).Error; err != nil {
+	var childCount int64
+	db.Model(&models.Department{}).Where("parent_id = ?", uint(deptID)).Count(&childCount)
+	if childCount > 0 {
+		errorResponse(c, 400, "该部门下仍有下级部门，请先移走或删除下级部门")
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Department{}, deptID).Error; err != nil {
+			return err
+		}
+		return audit.Deleted(tx, models.AuditEntityDepartment, dept.ID, dept)
+	})
+	if err != nil {
 		errorResponse(c, 500, "删除部门失败")
 		return
 	}
 	success(c, gin.H{"message": "删除成功"})
 }
+
+// RestoreDepartment 恢复已删除的部门
+func (dc *DepartmentController) RestoreDepartment(c *gin.Context) {
+	deptID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errorResponse(c, 400, "无效的部门ID")
+		return
+	}
+
+	expected, err := ifMatchVersion(c)
+	if err != nil {
+		errorResponse(c, 400, err.Error())
+		return
+	}
+
+	db := requestDB(c)
+	var dept models.Department
+	if err := db.Unscoped().First(&dept, deptID).Error; err != nil {
+		errorResponse(c, 404, "部门不存在")
+		return
+	}
+	if !dept.DeletedAt.Valid {
+		errorResponse(c, 400, "该部门未被删除")
+		return
+	}
+	if err := checkVersion(expected, dept.Version); err != nil {
+		versionConflict(c, err, &models.Department{}, dept.ID)
+		return
+	}
+	if dept.ParentID != nil {
+		var parent models.Department
+		if err := db.Unscoped().First(&parent, *dept.ParentID).Error; err == nil && parent.DeletedAt.Valid {
+			errorResponse(c, 400, "上级部门「"+parent.Name+"」已删除，请先恢复上级部门")
+			return
+		}
+	}
+
+	deletedAt := dept.DeletedAt.Time
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := service.UpdateVersioned(tx.Unscoped(), &models.Department{}, dept.ID, dept.Version, map[string]interface{}{"deleted_at": nil}); err != nil {
+			return err
+		}
+		if err := tx.First(&dept, dept.ID).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, models.AuditEntityDepartment, dept.ID, models.AuditActionRestore, models.AuditChanges{
+			"deleted_at": {Old: deletedAt, New: nil},
+		})
+	})
+	if errors.Is(err, service.ErrVersionConflict) {
+		versionConflict(c, err, &models.Department{}, dept.ID)
+		return
+	}
+	if err != nil {
+		errorResponse(c, 500, "恢复部门失败")
+		return
+	}
+	setETag(c, dept.Version)
+	success(c, dept)
+}
@@ -2,16 +2,26 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
 
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/service"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type EmployeeController struct{}
 
+// maxImportSize 导入文件大小上限
+const maxImportSize = 10 << 20
+
 // 响应结构
 type Response struct {
 	Code    int         `json:"code"`
@@ -45,6 +55,62 @@ func errorResponse(c *gin.Context, code int, message string) {
 	})
 }
 
+// toEmployeeResponse 转换为响应格式，需预加载 Department
+func toEmployeeResponse(emp models.Employee) models.EmployeeResponse {
+	resp := models.EmployeeResponse{
+		ID:           emp.ID,
+		EmployeeID:   emp.EmployeeID,
+		Name:         emp.Name,
+		Status:       emp.Status,
+		StatusText:   models.GetStatusText(emp.Status),
+		ArrivalDate:  emp.ArrivalDate,
+		JobTitle:     emp.JobTitle,
+		Position:     emp.Position,
+		DepartmentID: emp.DepartmentID,
+		Phone:        emp.Phone,
+		Email:        emp.Email,
+		Address:      emp.Address,
+		Remark:       emp.Remark,
+		CreatedAt:    emp.CreatedAt,
+		UpdatedAt:    emp.UpdatedAt,
+		Version:      emp.Version,
+	}
+	if emp.DeletedAt.Valid {
+		resp.DeletedAt = &emp.DeletedAt.Time
+	}
+	if emp.Department != nil {
+		resp.DepartmentNo = emp.Department.DeptNo
+		resp.Department = emp.Department.Name
+	}
+	return resp
+}
+
+// departmentExists 判断部门是否存在
+func departmentExists(db *gorm.DB, id uint) bool {
+	var count int64
+	db.Model(&models.Department{}).Where("id = ?", id).Count(&count)
+	return count > 0
+}
+
+// withDeleted 预加载关联时包含已删除的记录，历史调动等仍能显示原员工和部门
+func withDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// filterDeleted 按 deleted 参数筛选已删除的记录：
+// exclude (默认) 不含已删除记录，include 包含，only 只列出已删除记录
+func filterDeleted(query *gorm.DB, deleted string) (*gorm.DB, bool) {
+	switch deleted {
+	case "", "exclude":
+		return query, true
+	case "include":
+		return query.Unscoped(), true
+	case "only":
+		return query.Unscoped().Where("deleted_at IS NOT NULL"), true
+	}
+	return query, false
+}
+
 // GetEmployees 获取员工列表
 // @Summary 获取员工列表
 // @Description 获取员工列表，支持分页和筛选
@@ -55,7 +121,10 @@ func errorResponse(c *gin.Context, code int, message string) {
 // @Param page_size query int false "每页数量" default(10)
 // @Param name query string false "员工姓名"
 // @Param status query int false "员工状态"
-// @Param department query string false "部门名称"
+// @Param department query string false "部门名称或编号"
+// @Param department_id query int false "部门ID"
+// @Param include_sub query bool false "按部门ID筛选时包含下级部门的员工"
+// @Param deleted query string false "已删除员工: exclude (默认) / include / only"
 // @Success 200 {object} Response{data=PaginatedResponse}
 // @Router /api/employees [get]
 func (ec *EmployeeController) GetEmployees(c *gin.Context) {
@@ -65,6 +134,7 @@ func (ec *EmployeeController) GetEmployees(c *gin.Context) {
 	name := c.Query("name")
 	status := c.Query("status")
 	department := c.Query("department")
+	departmentID := c.Query("department_id")
 
 	// 确保分页参数有效
 	if page < 1 {
@@ -81,7 +151,11 @@ func (ec *EmployeeController) GetEmployees(c *gin.Context) {
 	db := database.GetDB()
 
 	// 构建查询
-	query := db.Model(&models.Employee{})
+	query, ok := filterDeleted(db.Model(&models.Employee{}).Preload("Department", withDeleted), c.Query("deleted"))
+	if !ok {
+		errorResponse(c, 400, "deleted 参数无效，可选 exclude、include、only")
+		return
+	}
 
 	// 添加筛选条件
 	if name != "" {
@@ -95,7 +169,27 @@ func (ec *EmployeeController) GetEmployees(c *gin.Context) {
 	}
 
 	if department != "" {
-		query = query.Where("department LIKE ?", "%"+department+"%")
+		query = query.Where("department_id IN (?)",
+			db.Model(&models.Department{}).Unscoped().Select("id").
+				Where("name LIKE ? OR dept_no LIKE ?", "%"+department+"%", "%"+department+"%"))
+	}
+
+	if departmentID != "" {
+		if c.Query("include_sub") == "true" {
+			id, err := strconv.ParseUint(departmentID, 10, 32)
+			if err != nil {
+				errorResponse(c, 400, "department_id 无效")
+				return
+			}
+			ids, err := service.DepartmentSubtree(db, uint(id))
+			if err != nil {
+				errorResponse(c, 500, err.Error())
+				return
+			}
+			query = query.Where("department_id IN ?", ids)
+		} else {
+			query = query.Where("department_id = ?", departmentID)
+		}
 	}
 
 	// 获取总数
@@ -117,23 +211,7 @@ func (ec *EmployeeController) GetEmployees(c *gin.Context) {
 	// 转换为响应格式
 	employeeResponses := make([]models.EmployeeResponse, len(employees))
 	for i, emp := range employees {
-		employeeResponses[i] = models.EmployeeResponse{
-			ID:          emp.ID,
-			EmployeeID:  emp.EmployeeID,
-			Name:        emp.Name,
-			Status:      emp.Status,
-			StatusText:  models.GetStatusText(emp.Status),
-			ArrivalDate: emp.ArrivalDate,
-			JobTitle:    emp.JobTitle,
-			Position:    emp.Position,
-			Department:  emp.Department,
-			Phone:       emp.Phone,
-			Email:       emp.Email,
-			Address:     emp.Address,
-			Remark:      emp.Remark,
-			CreatedAt:   emp.CreatedAt,
-			UpdatedAt:   emp.UpdatedAt,
-		}
+		employeeResponses[i] = toEmployeeResponse(emp)
 	}
 
 	// 返回分页响应
@@ -147,7 +225,7 @@ func (ec *EmployeeController) GetEmployees(c *gin.Context) {
 
 // GetEmployee 获取单个员工
 // @Summary 获取员工详情
-// @Description 根据ID获取员工详情
+// @Description 根据ID获取员工详情，已删除的员工同样可以查看
 // @Tags 员工管理
 // @Accept json
 // @Produce json
@@ -168,34 +246,111 @@ func (ec *EmployeeController) GetEmployee(c *gin.Context) {
 	var employee models.Employee
 
 	// 查询员工
-	err = db.First(&employee, employeeID).Error
+	err = db.Unscoped().Preload("Department", withDeleted).First(&employee, employeeID).Error
 	if err != nil {
 		errorResponse(c, 404, "员工不存在")
 		return
 	}
 
 	// 转换为响应格式
-	employeeResponse := models.EmployeeResponse{
-		ID:          employee.ID,
-		EmployeeID:  employee.EmployeeID,
-		Name:        employee.Name,
-		Status:      employee.Status,
-		StatusText:  models.GetStatusText(employee.Status),
-		ArrivalDate: employee.ArrivalDate,
-		JobTitle:    employee.JobTitle,
-		Position:    employee.Position,
-		Department:  employee.Department,
-		Phone:       employee.Phone,
-		Email:       employee.Email,
-		Address:     employee.Address,
-		Remark:      employee.Remark,
-		CreatedAt:   employee.CreatedAt,
-		UpdatedAt:   employee.UpdatedAt,
-	}
+	employeeResponse := toEmployeeResponse(employee)
 
+	setETag(c, employee.Version)
 	success(c, employeeResponse)
 }
 
+// GetEmployeeAsOf 获取员工在某一日期的信息
+// @Summary 按日期查询员工
+// @Description 根据调动记录和审计日志还原员工在指定日期 (当天结束时) 的部门、职位、职务和状态
+// @Tags 员工管理
+// @Produce json
+// @Param id path int true "员工ID"
+// @Param date query string true "日期 YYYY-MM-DD"
+// @Success 200 {object} Response{data=models.EmployeeAsOfResponse}
+// @Router /api/employees/{id}/as-of [get]
+func (ec *EmployeeController) GetEmployeeAsOf(c *gin.Context) {
+	employeeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errorResponse(c, 400, "无效的员工ID")
+		return
+	}
+	date, err := utils.ParseDate(c.Query("date"))
+	if err != nil {
+		errorResponse(c, 400, "date 格式错误，应为 YYYY-MM-DD")
+		return
+	}
+
+	db := database.GetDB()
+	var employee models.Employee
+	if err := db.Unscoped().First(&employee, employeeID).Error; err != nil {
+		errorResponse(c, 404, "员工不存在")
+		return
+	}
+
+	states, err := service.EmployeesAsOf(db, []models.Employee{employee}, date)
+	if err != nil {
+		errorResponse(c, 500, err.Error())
+		return
+	}
+	state := states[0]
+
+	// 部门名称取当前名称
+	if state.Employee.DepartmentID != nil {
+		var dept models.Department
+		if err := db.Unscoped().First(&dept, *state.Employee.DepartmentID).Error; err == nil {
+			state.Employee.Department = &dept
+		}
+	}
+
+	success(c, models.EmployeeAsOfResponse{
+		AsOf:     utils.FormatDate(date),
+		Employed: state.Employed,
+		Employee: toEmployeeResponse(state.Employee),
+		Warnings: state.Warnings,
+	})
+}
+
+// GetNextStatuses 获取员工可变更到的状态
+// @Summary 员工可变更的状态
+// @Description 按状态变更表列出员工当前状态可以变更到的状态，需通过调动审批的注明调动类型
+// @Tags 员工管理
+// @Produce json
+// @Param id path int true "员工ID"
+// @Success 200 {object} Response{data=models.NextStatusesResponse}
+// @Router /api/employees/{id}/next-statuses [get]
+func (ec *EmployeeController) GetNextStatuses(c *gin.Context) {
+	employeeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errorResponse(c, 400, "无效的员工ID")
+		return
+	}
+
+	var employee models.Employee
+	if err := database.GetDB().First(&employee, employeeID).Error; err != nil {
+		errorResponse(c, 404, "员工不存在")
+		return
+	}
+
+	resp := models.NextStatusesResponse{
+		Status:     employee.Status,
+		StatusText: models.GetStatusText(employee.Status),
+		Next:       []models.NextStatus{},
+	}
+	for _, t := range models.NextStatuses(employee.Status) {
+		next := models.NextStatus{
+			Status:     int(t.To),
+			StatusText: models.GetStatusText(int(t.To)),
+		}
+		if t.Via != 0 {
+			next.TransferType = t.Via
+			next.TransferTypeText = models.GetTransferTypeText(t.Via)
+		}
+		resp.Next = append(resp.Next, next)
+	}
+
+	success(c, resp)
+}
+
 // CreateEmployee 创建员工
 // @Summary 创建员工
 // @Description 创建新员工
@@ -214,62 +369,132 @@ func (ec *EmployeeController) CreateEmployee(c *gin.Context) {
 		return
 	}
 
-	// 验证员工编号是否已存在
-	db := database.GetDB()
-	var count int64
-	db.Model(&models.Employee{}).Where("employee_id = ?", req.EmployeeID).Count(&count)
-	if count > 0 {
+	// 验证员工编号是否已存在 (已删除的员工仍占用编号)
+	db := requestDB(c)
+	var existing models.Employee
+	if err := db.Unscoped().Where("employee_id = ?", req.EmployeeID).First(&existing).Error; err == nil {
+		if existing.DeletedAt.Valid {
+			errorResponse(c, 400, "员工编号已被已删除的员工使用，如需恢复请使用恢复功能")
+			return
+		}
 		errorResponse(c, 400, "员工编号已存在")
 		return
 	}
 
+	var departmentID *uint
+	if req.DepartmentID != 0 {
+		if !departmentExists(db, req.DepartmentID) {
+			errorResponse(c, 400, "所在部门不存在")
+			return
+		}
+		departmentID = &req.DepartmentID
+	}
+
 	// 创建员工对象
 	employee := models.Employee{
-		EmployeeID:  req.EmployeeID,
-		Name:        req.Name,
-		Status:      req.Status,
-		ArrivalDate: req.ArrivalDate,
-		JobTitle:    req.JobTitle,
-		Position:    req.Position,
-		Department:  req.Department,
-		Phone:       req.Phone,
-		Email:       req.Email,
-		Address:     req.Address,
-		Remark:      req.Remark,
+		EmployeeID:   req.EmployeeID,
+		Name:         req.Name,
+		Status:       req.Status,
+		ArrivalDate:  req.ArrivalDate,
+		JobTitle:     req.JobTitle,
+		Position:     req.Position,
+		DepartmentID: departmentID,
+		Phone:        req.Phone,
+		Email:        req.Email,
+		Address:      req.Address,
+		Remark:       req.Remark,
 	}
 
-	// 保存到数据库
-	result := db.Create(&employee)
-	if result.Error != nil {
-		errorResponse(c, 500, "创建员工失败: "+result.Error.Error())
+	// 保存到数据库，并记录审计日志
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&employee).Error; err != nil {
+			return err
+		}
+		return audit.Created(tx, models.AuditEntityEmployee, employee.ID, employee)
+	})
+	if err != nil {
+		errorResponse(c, 500, "创建员工失败: "+err.Error())
 		return
 	}
 
 	// 查询刚创建的员工（为了获取完整的字段）
-	db.First(&employee, employee.ID)
+	db.Preload("Department").First(&employee, employee.ID)
 
 	// 返回响应
-	employeeResponse := models.EmployeeResponse{
-		ID:          employee.ID,
-		EmployeeID:  employee.EmployeeID,
-		Name:        employee.Name,
-		Status:      employee.Status,
-		StatusText:  models.GetStatusText(employee.Status),
-		ArrivalDate: employee.ArrivalDate,
-		JobTitle:    employee.JobTitle,
-		Position:    employee.Position,
-		Department:  employee.Department,
-		Phone:       employee.Phone,
-		Email:       employee.Email,
-		Address:     employee.Address,
-		Remark:      employee.Remark,
-		CreatedAt:   employee.CreatedAt,
-		UpdatedAt:   employee.UpdatedAt,
-	}
+	employeeResponse := toEmployeeResponse(employee)
 
 	success(c, employeeResponse)
 }
 
+// ImportEmployees 批量导入员工
+// @Summary 导入员工
+// @Description 上传 CSV (兼容导出格式) 或 XLSX 文件，按员工编号新增或更新员工；任一行校验失败时不导入任何数据
+// @Tags 员工管理
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV 或 XLSX 文件"
+// @Param dry_run query bool false "仅校验，返回每行的错误，不写入数据库"
+// @Success 200 {object} Response{data=service.ImportResult}
+// @Router /api/employees/import [post]
+func (ec *EmployeeController) ImportEmployees(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		errorResponse(c, 400, "请上传文件 (表单字段 file)")
+		return
+	}
+	if fileHeader.Size > maxImportSize {
+		errorResponse(c, 400, "文件不能超过 10MB")
+		return
+	}
+
+	var format string
+	switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+	case ".csv":
+		format = service.ImportFormatCSV
+	case ".xlsx":
+		format = service.ImportFormatXLSX
+	default:
+		errorResponse(c, 400, "仅支持 .csv 和 .xlsx 文件")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		errorResponse(c, 400, "读取文件失败")
+		return
+	}
+	defer file.Close()
+
+	rows, err := service.ReadImportRows(file, format)
+	if err != nil {
+		errorResponse(c, 400, err.Error())
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	result, err := service.ImportEmployees(requestDB(c), rows, dryRun)
+	if errors.Is(err, service.ErrVersionConflict) {
+		conflictResponse(c, http.StatusConflict, "导入失败: "+err.Error(), nil, 0)
+		return
+	}
+	if err != nil {
+		errorResponse(c, 500, "导入失败: "+err.Error())
+		return
+	}
+
+	// 正式导入时存在错误行，整批不导入，同时返回每行的错误
+	if !dryRun && len(result.Errors) > 0 {
+		c.JSON(http.StatusOK, Response{
+			Code:    400,
+			Message: "数据校验未通过，未导入任何记录",
+			Data:    result,
+		})
+		return
+	}
+
+	success(c, result)
+}
+
 // UpdateEmployee 更新员工
 // @Summary 更新员工信息
 // @Description 更新员工信息
@@ -296,8 +521,13 @@ func (ec *EmployeeController) UpdateEmployee(c *gin.Context) {
 		errorResponse(c, 400, "请求参数错误: "+err.Error())
 		return
 	}
+	expected, err := ifMatchVersion(c)
+	if err != nil {
+		errorResponse(c, 400, err.Error())
+		return
+	}
 
-	db := database.GetDB()
+	db := requestDB(c)
 	var employee models.Employee
 
 	// 查找员工
@@ -306,6 +536,11 @@ func (ec *EmployeeController) UpdateEmployee(c *gin.Context) {
 		errorResponse(c, 404, "员工不存在")
 		return
 	}
+	// 客户端读取后员工已被修改时拒绝，避免覆盖他人的修改
+	if err := checkVersion(expected, employee.Version); err != nil {
+		versionConflict(c, err, &models.Employee{}, employee.ID)
+		return
+	}
 
 	// 更新字段
 	updateData := make(map[string]interface{})
@@ -314,7 +549,12 @@ func (ec *EmployeeController) UpdateEmployee(c *gin.Context) {
 		updateData["name"] = req.Name
 	}
 
-	if req.Status > 0 && req.Status <= 6 {
+	if req.Status > 0 && req.Status <= 6 && req.Status != employee.Status {
+		// 状态变更需符合状态变更表，离退休等只能通过调动审批生效
+		if err := service.CheckStatusChange(employee.Status, req.Status); err != nil {
+			errorResponse(c, 400, err.Error())
+			return
+		}
 		updateData["status"] = req.Status
 	}
 
@@ -326,8 +566,12 @@ func (ec *EmployeeController) UpdateEmployee(c *gin.Context) {
 		updateData["position"] = req.Position
 	}
 
-	if req.Department != "" {
-		updateData["department"] = req.Department
+	if req.DepartmentID != 0 {
+		if !departmentExists(db, req.DepartmentID) {
+			errorResponse(c, 400, "所在部门不存在")
+			return
+		}
+		updateData["department_id"] = req.DepartmentID
 	}
 
 	if req.Phone != "" {
@@ -346,41 +590,40 @@ func (ec *EmployeeController) UpdateEmployee(c *gin.Context) {
 		updateData["remark"] = req.Remark
 	}
 
-	// 更新数据库
-	result := db.Model(&employee).Updates(updateData)
-	if result.Error != nil {
-		errorResponse(c, 500, "更新员工失败: "+result.Error.Error())
+	// 按读取时的版本号更新数据库，并记录审计日志；期间被其他人修改过时返回 409
+	before := employee
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := service.UpdateVersioned(tx, &models.Employee{}, employee.ID, employee.Version, updateData); err != nil {
+			return err
+		}
+		var after models.Employee
+		if err := tx.First(&after, employee.ID).Error; err != nil {
+			return err
+		}
+		return audit.Updated(tx, models.AuditEntityEmployee, employee.ID, before, after)
+	})
+	if errors.Is(err, service.ErrVersionConflict) {
+		versionConflict(c, err, &models.Employee{}, employee.ID)
+		return
+	}
+	if err != nil {
+		errorResponse(c, 500, "更新员工失败: "+err.Error())
 		return
 	}
 
 	// 重新查询更新后的员工
-	db.First(&employee, employeeID)
+	db.Preload("Department").First(&employee, employeeID)
 
 	// 返回响应
-	employeeResponse := models.EmployeeResponse{
-		ID:          employee.ID,
-		EmployeeID:  employee.EmployeeID,
-		Name:        employee.Name,
-		Status:      employee.Status,
-		StatusText:  models.GetStatusText(employee.Status),
-		ArrivalDate: employee.ArrivalDate,
-		JobTitle:    employee.JobTitle,
-		Position:    employee.Position,
-		Department:  employee.Department,
-		Phone:       employee.Phone,
-		Email:       employee.Email,
-		Address:     employee.Address,
-		Remark:      employee.Remark,
-		CreatedAt:   employee.CreatedAt,
-		UpdatedAt:   employee.UpdatedAt,
-	}
+	employeeResponse := toEmployeeResponse(employee)
 
+	setETag(c, employee.Version)
 	success(c, employeeResponse)
 }
 
 // DeleteEmployee 删除员工
 // @Summary 删除员工
-// @Description 删除员工
+// @Description 软删除员工，记录保留并可恢复，超过保留期限后由管理员清除
 // @Tags 员工管理
 // @Accept json
 // @Produce json
@@ -396,19 +639,21 @@ func (ec *EmployeeController) DeleteEmployee(c *gin.Context) {
 		return
 	}
 
-	db := database.GetDB()
+	db := requestDB(c)
 
-	var count int64
-	db.Model(&models.Employee{}).Where("id = ?", employeeID).Count(&count)
-	if count == 0 {
+	var employee models.Employee
+	if err := db.First(&employee, employeeID).Error; err != nil {
 		errorResponse(c, 404, "员工不存在")
 		return
 	}
 
+	// 调动记录随员工保留，只有尚未生效的调动需要先撤回或取消
 	var transferCount int64
-	db.Model(&models.Transfer{}).Where("employee_id = ?", uint(employeeID)).Count(&transferCount)
+	db.Model(&models.Transfer{}).
+		Where("employee_id = ? AND status IN ?", uint(employeeID), []int{models.TransferStatusPending, models.TransferStatusApproved}).
+		Count(&transferCount)
 	if transferCount > 0 {
-		errorResponse(c, 400, "该员工存在调动记录，无法删除")
+		errorResponse(c, 400, "该员工存在未生效的调动申请，请先撤回或取消")
 		return
 	}
 
@@ -419,9 +664,14 @@ func (ec *EmployeeController) DeleteEmployee(c *gin.Context) {
 		return
 	}
 
-	result := db.Delete(&models.Employee{}, employeeID)
-	if result.Error != nil {
-		errorResponse(c, 500, "删除员工失败: "+result.Error.Error())
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Employee{}, employeeID).Error; err != nil {
+			return err
+		}
+		return audit.Deleted(tx, models.AuditEntityEmployee, employee.ID, employee)
+	})
+	if err != nil {
+		errorResponse(c, 500, "删除员工失败: "+err.Error())
 		return
 	}
 
@@ -430,3 +680,65 @@ func (ec *EmployeeController) DeleteEmployee(c *gin.Context) {
 		"id":      employeeID,
 	})
 }
+
+// RestoreEmployee 恢复已删除的员工
+// @Summary 恢复员工
+// @Description 恢复已删除的员工，所在部门已删除时需先恢复部门
+// @Tags 员工管理
+// @Produce json
+// @Param id path int true "员工ID"
+// @Success 200 {object} Response{data=models.EmployeeResponse}
+// @Router /api/employees/{id}/restore [put]
+func (ec *EmployeeController) RestoreEmployee(c *gin.Context) {
+	employeeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errorResponse(c, 400, "无效的员工ID")
+		return
+	}
+
+	expected, err := ifMatchVersion(c)
+	if err != nil {
+		errorResponse(c, 400, err.Error())
+		return
+	}
+
+	db := requestDB(c)
+	var employee models.Employee
+	if err := db.Unscoped().First(&employee, employeeID).Error; err != nil {
+		errorResponse(c, 404, "员工不存在")
+		return
+	}
+	if !employee.DeletedAt.Valid {
+		errorResponse(c, 400, "该员工未被删除")
+		return
+	}
+	if err := checkVersion(expected, employee.Version); err != nil {
+		versionConflict(c, err, &models.Employee{}, employee.ID)
+		return
+	}
+	if employee.DepartmentID != nil && !departmentExists(db, *employee.DepartmentID) {
+		errorResponse(c, 400, "员工所在部门已删除，请先恢复部门")
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := service.UpdateVersioned(tx.Unscoped(), &models.Employee{}, employee.ID, employee.Version, map[string]interface{}{"deleted_at": nil}); err != nil {
+			return err
+		}
+		return audit.Record(tx, models.AuditEntityEmployee, employee.ID, models.AuditActionRestore, models.AuditChanges{
+			"deleted_at": {Old: employee.DeletedAt.Time, New: nil},
+		})
+	})
+	if errors.Is(err, service.ErrVersionConflict) {
+		versionConflict(c, err, &models.Employee{}, employee.ID)
+		return
+	}
+	if err != nil {
+		errorResponse(c, 500, "恢复员工失败: "+err.Error())
+		return
+	}
+
+	db.Preload("Department").First(&employee, employeeID)
+	setETag(c, employee.Version)
+	success(c, toEmployeeResponse(employee))
+}
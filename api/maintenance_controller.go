@@ -0,0 +1,32 @@
+// api/maintenance_controller.go
+package api
+
+import (
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/service"
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceController 数据维护，PurgeAfter 为已删除记录的保留期限 (retention.purge_after)
+type MaintenanceController struct {
+	PurgeAfter time.Duration
+}
+
+// PurgeDeleted 彻底清除删除时间超过保留期限的员工和部门
+// dry_run=true 时只返回将被清除的记录，不做修改
+func (mc *MaintenanceController) PurgeDeleted(c *gin.Context) {
+	if mc.PurgeAfter <= 0 {
+		errorResponse(c, 400, "未配置保留期限 (retention.purge_after)，不允许清除已删除的记录")
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	before := time.Now().Add(-mc.PurgeAfter)
+	result, err := service.PurgeDeleted(requestDB(c), before, dryRun)
+	if err != nil {
+		errorResponse(c, 500, "清除失败: "+err.Error())
+		return
+	}
+	success(c, result)
+}
@@ -2,11 +2,18 @@
 package api
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/service"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -15,20 +22,79 @@ type TransferController struct{}
 
 // CreateTransferRequest 创建调动申请请求
 type CreateTransferRequest struct {
-	EmployeeID   uint   `json:"employee_id" binding:"required"`
-	Type         int    `json:"type" binding:"required"` // 1-部门调动, 2-职位调动, 3-离退休
-	TransferDate string `json:"transfer_date" binding:"required"`
-	FromDeptID   uint   `json:"from_dept_id"`
-	ToDeptID     uint   `json:"to_dept_id"` // 如果是部门调动，必填
-	Reason       string `json:"reason"`
+	EmployeeID        uint   `json:"employee_id" binding:"required"`
+	Type              int    `json:"type" binding:"required,oneof=1 2 3 4 5 6 7"` // 1-部门调动, 2-职位调动, 3-离退休, 4-离职, 5-返聘, 6-试用转正, 7-转兼职
+	TransferDate      string `json:"transfer_date" binding:"required"`
+	FromDeptID        uint   `json:"from_dept_id"`
+	ToDeptID          uint   `json:"to_dept_id"`          // 如果是部门调动，必填
+	FromPosition      string `json:"from_position"`       // 职位调动：原职位，不填则取员工当前职位
+	ToPosition        string `json:"to_position"`         // 职位调动：新职位
+	FromJobTitle      string `json:"from_job_title"`      // 职位调动：原职务，不填则取员工当前职务
+	ToJobTitle        string `json:"to_job_title"`        // 职位调动：新职务
+	LastWorkingDay    string `json:"last_working_day"`    // 离职：最后工作日，必填
+	ContractStartDate string `json:"contract_start_date"` // 返聘：合同开始日期，必填
+	ContractEndDate   string `json:"contract_end_date"`   // 返聘：合同结束日期，必填
+	Reason            string `json:"reason"`
 }
 
-// ApproveTransferRequest 审批请求
+// ApproveTransferRequest 审批请求 (审批人从 Token 获取)
 type ApproveTransferRequest struct {
-	Status     int  `json:"status" binding:"required,oneof=2 3"` // 2-通过, 3-驳回
-	ApproverID uint `json:"approver_id"`                         // 实际应从Token获取
+	Status  int    `json:"status" binding:"required,oneof=2 3"` // 2-通过, 3-驳回
+	Comment string `json:"comment"`                             // 审批意见
 }
 
+// CancelTransferRequest 撤回/取消/撤销请求
+type CancelTransferRequest struct {
+	Reason string `json:"reason"` // 取消、撤销时必填
+}
+
+// BatchTransferRequest 批量提交调动申请，员工共用类型、生效日期、调入部门和原因
+type BatchTransferRequest struct {
+	EmployeeIDs       []uint `json:"employee_ids" binding:"required,min=1,max=200"` // 一次最多 200 人
+	Type              int    `json:"type" binding:"required,oneof=1 2 3 4 5 6 7"`
+	TransferDate      string `json:"transfer_date" binding:"required"`
+	ToDeptID          uint   `json:"to_dept_id"`          // 部门调动：调入部门
+	ToPosition        string `json:"to_position"`         // 职位调动：新职位
+	ToJobTitle        string `json:"to_job_title"`        // 职位调动：新职务
+	LastWorkingDay    string `json:"last_working_day"`    // 离职：最后工作日
+	ContractStartDate string `json:"contract_start_date"` // 返聘：合同开始日期
+	ContractEndDate   string `json:"contract_end_date"`   // 返聘：合同结束日期
+	Reason            string `json:"reason"`
+}
+
+// BatchApproveRequest 批量审批请求，所有记录使用相同的审批结果和意见
+type BatchApproveRequest struct {
+	IDs     []uint `json:"ids" binding:"required,min=1,max=200"` // 一次最多 200 条
+	Status  int    `json:"status" binding:"required,oneof=2 3"`  // 2-通过, 3-驳回
+	Comment string `json:"comment"`
+}
+
+// BatchItemResult 批量操作中一条记录的结果
+type BatchItemResult struct {
+	EmployeeID uint                 `json:"employee_id,omitempty"`
+	TransferID uint                 `json:"transfer_id,omitempty"`
+	Status     int                  `json:"status,omitempty"` // 处理后的调动状态
+	Message    string               `json:"message,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	Errors     []service.FieldError `json:"errors,omitempty"` // 提交申请时各字段的校验错误
+}
+
+// BatchResult 批量操作结果，Items 与请求中的顺序一致
+type BatchResult struct {
+	Committed bool              `json:"committed"` // 是否已写入 (任一条失败时整批回滚)
+	Total     int               `json:"total"`
+	Failed    int               `json:"failed"`
+	Items     []BatchItemResult `json:"items"`
+}
+
+func (r *BatchResult) fail(item *BatchItemResult, message string) {
+	item.Error = message
+	r.Failed++
+}
+
+// errBatchRejected 批量操作中有记录失败，用于回滚整批
+var errBatchRejected = errors.New("批量操作中存在失败的记录")
+
 // CreateTransfer 创建调动/离退休申请
 func (tc *TransferController) CreateTransfer(c *gin.Context) {
 	var req CreateTransferRequest
@@ -37,55 +103,115 @@ func (tc *TransferController) CreateTransfer(c *gin.Context) {
 		return
 	}
 
-	db := database.GetDB()
-
-	// 验证员工是否存在
-	var emp models.Employee
-	if err := db.First(&emp, req.EmployeeID).Error; err != nil {
-		errorResponse(c, 404, "员工不存在")
+	submitterID, ok := currentUserID(c)
+	if !ok {
+		errorResponse(c, 401, "未认证")
 		return
 	}
 
-	// 处理部门ID，0 视为未设置
-	var fromDeptID *uint
-	if req.FromDeptID != 0 {
-		fromDeptID = &req.FromDeptID
-		// 校验调出部门是否存在
-		var fromDept models.Department
-		if err := db.First(&fromDept, req.FromDeptID).Error; err != nil {
-			errorResponse(c, 400, "调出部门不存在")
-			return
-		}
+	// 申请与审批链在同一事务中创建
+	var transfer *models.Transfer
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		var err error
+		transfer, err = submitTransfer(tx, req, submitterID)
+		return err
+	})
+	var reqErr *requestError
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &reqErr):
+		errorResponse(c, reqErr.Code, reqErr.Message)
+		return
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusOK, Response{
+			Code:    400,
+			Message: validationErr.Error(),
+			Data:    gin.H{"errors": validationErr.Errors},
+		})
+		return
+	case err != nil:
+		errorResponse(c, 500, "创建申请失败")
+		return
 	}
 
-	var toDeptID *uint
-	if req.ToDeptID != 0 {
-		toDeptID = &req.ToDeptID
-		// 校验调入部门是否存在
-		var toDept models.Department
-		if err := db.First(&toDept, req.ToDeptID).Error; err != nil {
-			errorResponse(c, 400, "调入部门不存在")
-			return
-		}
+	success(c, transfer)
+}
+
+// requestError 请求数据校验未通过，Code 为响应码
+type requestError struct {
+	Code    int
+	Message string
+}
+
+func (e *requestError) Error() string {
+	return e.Message
+}
+
+// submitTransfer 校验并创建调动申请及其审批链，需在事务中调用。
+// 员工不存在时返回 *requestError，申请内容校验未通过时返回 *service.ValidationError。
+func submitTransfer(tx *gorm.DB, req CreateTransferRequest, submitterID uint) (*models.Transfer, error) {
+	// 验证员工是否存在
+	var emp models.Employee
+	if err := tx.First(&emp, req.EmployeeID).Error; err != nil {
+		return nil, &requestError{Code: 404, Message: "员工不存在"}
 	}
 
 	transfer := models.Transfer{
 		EmployeeID:   req.EmployeeID,
 		Type:         req.Type,
 		TransferDate: req.TransferDate,
-		FromDeptID:   fromDeptID,
-		ToDeptID:     toDeptID,
 		Reason:       req.Reason,
 		Status:       models.TransferStatusPending,
+		SubmitterID:  submitterID,
 		CreatedAt:    time.Now(),
 	}
 
-	if err := db.Omit("ApproverID", "ApprovedAt").Create(&transfer).Error; err != nil {
-		errorResponse(c, 500, "创建申请失败")
-		return
+	// 只保留与调动类型相关的字段；0 和空值视为未设置
+	if req.FromDeptID != 0 {
+		transfer.FromDeptID = &req.FromDeptID
+	}
+	switch req.Type {
+	case models.TransferTypeDepartment:
+		// 调出部门不填时取员工当前部门，审批链中的调出部门主管据此确定
+		if transfer.FromDeptID == nil {
+			transfer.FromDeptID = emp.DepartmentID
+		}
+		if req.ToDeptID != 0 {
+			transfer.ToDeptID = &req.ToDeptID
+		}
+	case models.TransferTypePosition:
+		// 记录调动前的职位/职务，审批时据此校验员工信息未被改动
+		transfer.FromPosition, transfer.FromJobTitle = req.FromPosition, req.FromJobTitle
+		if transfer.FromPosition == "" {
+			transfer.FromPosition = emp.Position
+		}
+		if transfer.FromJobTitle == "" {
+			transfer.FromJobTitle = emp.JobTitle
+		}
+		transfer.ToPosition, transfer.ToJobTitle = req.ToPosition, req.ToJobTitle
+	case models.TransferTypeResignation:
+		transfer.LastWorkingDay = &req.LastWorkingDay
+	case models.TransferTypeRehire:
+		transfer.ContractStartDate, transfer.ContractEndDate = &req.ContractStartDate, &req.ContractEndDate
 	}
 
-	success(c, transfer)
+	// 按调动类型校验部门、职位、状态、日期以及重复申请
+	if err := service.ValidateTransfer(tx, &emp, &transfer); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Omit("ApproverID", "ApprovedAt").Create(&transfer).Error; err != nil {
+		return nil, err
+	}
+	steps, err := service.CreateApprovalSteps(tx, &transfer)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Steps = steps
+	if err := audit.Created(tx, models.AuditEntityTransfer, transfer.ID, transfer); err != nil {
+		return nil, err
+	}
+	return &transfer, nil
 }
 
 // GetTransfers 获取调动记录列表
@@ -94,7 +220,8 @@ func (tc *TransferController) GetTransfers(c *gin.Context) {
 	status := c.Query("status") // 1-待审批
 
 	db := database.GetDB()
-	query := db.Model(&models.Transfer{}).Preload("Employee").Preload("FromDept").Preload("ToDept")
+	query := db.Model(&models.Transfer{}).Preload("Employee", withDeleted).Preload("FromDept", withDeleted).Preload("ToDept", withDeleted).
+		Preload("Submitter").Preload("Approver").Preload("Steps", orderedSteps).Preload("Steps.Dept", withDeleted).Preload("Steps.Actor")
 
 	if employeeID != "" {
 		query = query.Where("employee_id = ?", employeeID)
@@ -109,79 +236,471 @@ func (tc *TransferController) GetTransfers(c *gin.Context) {
 	success(c, transfers)
 }
 
+// GetMyApprovals 获取当前步骤等待我审批的调动
+func (tc *TransferController) GetMyApprovals(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		errorResponse(c, 401, "未认证")
+		return
+	}
+
+	db := database.GetDB()
+	transfers, err := service.PendingForUser(db, user)
+	if err != nil {
+		errorResponse(c, 500, "获取待审批列表失败")
+		return
+	}
+	if len(transfers) > 0 {
+		ids := make([]uint, len(transfers))
+		for i, t := range transfers {
+			ids[i] = t.ID
+		}
+		transfers = nil
+		db.Preload("Employee", withDeleted).Preload("FromDept", withDeleted).Preload("ToDept", withDeleted).Preload("Submitter").
+			Preload("Steps", orderedSteps).Preload("Steps.Dept", withDeleted).Preload("Steps.Actor").
+			Where("id IN ?", ids).Order("created_at").Find(&transfers)
+	}
+
+	success(c, transfers)
+}
+
 // ApproveTransfer 审批调动 (核心逻辑)
-// 审批通过后，会自动更新 Employee 表的数据
+// 按审批链逐级审批：任一步驳回即结束，最后一步通过后且到生效日期时更新 Employee 表
 func (tc *TransferController) ApproveTransfer(c *gin.Context) {
-	id := c.Param("id")
 	var req ApproveTransferRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		errorResponse(c, 400, "参数错误")
 		return
 	}
 
-	db := database.GetDB()
-	var transfer models.Transfer
-	if err := db.First(&transfer, id).Error; err != nil {
+	approver, ok := currentUser(c)
+	if !ok {
+		errorResponse(c, 401, "未认证")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
 		errorResponse(c, 404, "调动记录不存在")
 		return
 	}
+	expected, err := ifMatchVersion(c)
+	if err != nil {
+		errorResponse(c, 400, err.Error())
+		return
+	}
+
+	var transfer *models.Transfer
+	var message string
+	err = requestDB(c).Transaction(func(tx *gorm.DB) error {
+		var err error
+		transfer, message, err = approveTransfer(tx, uint(id), expected, approver, req.Status == models.TransferStatusApproved, req.Comment, time.Now())
+		return err
+	})
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		if reqErr.Code == http.StatusConflict || reqErr.Code == http.StatusPreconditionFailed {
+			conflictResponse(c, reqErr.Code, reqErr.Message, &models.Transfer{}, uint(id))
+			return
+		}
+		errorResponse(c, reqErr.Code, reqErr.Message)
+		return
+	}
+	if err != nil {
+		errorResponse(c, 500, "审批处理失败: "+err.Error())
+		return
+	}
+	setETag(c, transfer.Version)
+	success(c, gin.H{"message": message, "version": transfer.Version})
+}
 
+// approveTransfer 处理调动当前的审批步骤，需在事务中调用。
+// 最后一步通过且已到生效日期时立即修改员工基本表并标记为已完成；
+// 未到日期的保持"已批准"，由定时任务在生效日处理。返回处理后的调动和结果说明。
+// expected 为客户端读取时的版本号 (If-Match)，0 表示不校验，不一致时返回 412；与他人同时审批时返回 409。
+func approveTransfer(tx *gorm.DB, id, expected uint, approver *models.User, approve bool, comment string, now time.Time) (*models.Transfer, string, error) {
+	var transfer models.Transfer
+	if err := tx.First(&transfer, id).Error; err != nil {
+		return nil, "", &requestError{Code: 404, Message: "调动记录不存在"}
+	}
+	if err := checkVersion(expected, transfer.Version); err != nil {
+		return nil, "", &requestError{Code: http.StatusPreconditionFailed, Message: err.Error()}
+	}
 	if transfer.Status != models.TransferStatusPending {
-		errorResponse(c, 400, "该记录已审批，无法重复操作")
+		return nil, "", &requestError{Code: 400, Message: "该记录已审批，无法重复操作"}
+	}
+	// 禁止审批自己提交的申请
+	if transfer.SubmitterID != 0 && transfer.SubmitterID == approver.ID {
+		return nil, "", &requestError{Code: 403, Message: "不能审批自己提交的申请"}
+	}
+
+	// 1. 处理当前审批步骤，流程未结束时调动保持待审批
+	review, err := service.ReviewTransfer(tx, &transfer, approver, approve, comment, now)
+	var statusErr *service.StatusTransitionError
+	switch {
+	case errors.Is(err, service.ErrNotApprover):
+		return nil, "", &requestError{Code: 403, Message: err.Error()}
+	case errors.Is(err, service.ErrVersionConflict):
+		return nil, "", &requestError{Code: http.StatusConflict, Message: err.Error()}
+	case errors.Is(err, service.ErrNotPending), errors.As(err, &statusErr):
+		return nil, "", &requestError{Code: 400, Message: err.Error()}
+	case err != nil:
+		return nil, "", err
+	}
+
+	// 驳回或仍有后续步骤，不修改员工表
+	switch {
+	case transfer.Status == models.TransferStatusRejected:
+		return &transfer, "已驳回该申请", nil
+	case !review.Finished:
+		return &transfer, fmt.Sprintf("第 %d 步审批通过，等待下一步审批", review.Step.StepNo), nil
+	case !service.IsDue(&transfer, now):
+		return &transfer, "审批完成，将于 " + utils.DateOnly(transfer.TransferDate) + " 生效", nil
+	}
+
+	// 2. 已到生效日期，立即修改员工基本表并标记为已完成
+	applied, err := service.CompleteTransfer(tx, &transfer, now)
+	if errors.As(err, &statusErr) {
+		return nil, "", &requestError{Code: 400, Message: err.Error()}
+	}
+	if errors.Is(err, service.ErrVersionConflict) {
+		return nil, "", &requestError{Code: http.StatusConflict, Message: "员工信息已被其他人修改，请刷新后重试"}
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if !applied {
+		return &transfer, "审批完成，将于 " + utils.DateOnly(transfer.TransferDate) + " 生效", nil
+	}
+	return &transfer, "审批完成，员工信息已同步更新", nil
+}
+
+// BatchTransfers 批量提交调动申请：多名员工使用相同的类型、生效日期、调入部门和原因。
+// 所有申请在同一事务中创建，任一条校验失败时全部不提交，并返回每一条的结果。
+func (tc *TransferController) BatchTransfers(c *gin.Context) {
+	var req BatchTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, 400, "参数错误: "+err.Error())
 		return
 	}
 
-	// 开启事务
-	err := db.Transaction(func(tx *gorm.DB) error {
-		// 1. 更新调动表状态
-		transfer.Status = req.Status
-		transfer.ApproverID = req.ApproverID
-		now := time.Now()
-		transfer.ApprovedAt = &now
+	submitterID, ok := currentUserID(c)
+	if !ok {
+		errorResponse(c, 401, "未认证")
+		return
+	}
 
-		if err := tx.Save(&transfer).Error; err != nil {
-			return err
-		}
+	result := &BatchResult{Total: len(req.EmployeeIDs), Items: make([]BatchItemResult, len(req.EmployeeIDs))}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		seen := make(map[uint]bool, len(req.EmployeeIDs))
+		for i, employeeID := range req.EmployeeIDs {
+			item := &result.Items[i]
+			item.EmployeeID = employeeID
+			if seen[employeeID] {
+				result.fail(item, "员工重复出现")
+				continue
+			}
+			seen[employeeID] = true
 
-		// 如果是驳回(3)，则不修改员工表，直接返回
-		if req.Status == models.TransferStatusRejected {
-			return nil
+			transfer, err := submitTransfer(tx, CreateTransferRequest{
+				EmployeeID:        employeeID,
+				Type:              req.Type,
+				TransferDate:      req.TransferDate,
+				ToDeptID:          req.ToDeptID,
+				ToPosition:        req.ToPosition,
+				ToJobTitle:        req.ToJobTitle,
+				LastWorkingDay:    req.LastWorkingDay,
+				ContractStartDate: req.ContractStartDate,
+				ContractEndDate:   req.ContractEndDate,
+				Reason:            req.Reason,
+			}, submitterID)
+			var reqErr *requestError
+			var validationErr *service.ValidationError
+			switch {
+			case errors.As(err, &reqErr):
+				result.fail(item, reqErr.Message)
+				continue
+			case errors.As(err, &validationErr):
+				result.fail(item, validationErr.Error())
+				item.Errors = validationErr.Errors
+				continue
+			case err != nil:
+				return err
+			}
+			item.TransferID = transfer.ID
+			item.Status = transfer.Status
+			item.Message = "已提交"
 		}
-
-		// 2. 如果是批准(2)，则修改员工基本表 (Requirement 2 & 3)
-		var employee models.Employee
-		if err := tx.First(&employee, transfer.EmployeeID).Error; err != nil {
-			return err
+		if result.Failed > 0 {
+			return errBatchRejected
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchRejected) {
+		for i := range result.Items {
+			result.Items[i].TransferID = 0 // 已回滚，申请未创建
 		}
+	}
+	batchResponse(c, result, err, "批量提交失败")
+}
+
+// ApproveBatch 批量审批调动，所有记录使用相同的审批结果和意见。
+// 在同一事务中逐条处理当前审批步骤，任一条失败时全部回滚，并返回每一条的结果。
+func (tc *TransferController) ApproveBatch(c *gin.Context) {
+	var req BatchApproveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, 400, "参数错误: "+err.Error())
+		return
+	}
+
+	approver, ok := currentUser(c)
+	if !ok {
+		errorResponse(c, 401, "未认证")
+		return
+	}
 
-		// 根据调动类型更新员工信息
-		if transfer.Type == models.TransferTypeDepartment {
-			// 部门调动：查询新部门名称并更新
-			if transfer.ToDeptID == nil {
-				return fmt.Errorf("目标部门未设置")
+	result := &BatchResult{Total: len(req.IDs), Items: make([]BatchItemResult, len(req.IDs))}
+	err := requestDB(c).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		seen := make(map[uint]bool, len(req.IDs))
+		for i, id := range req.IDs {
+			item := &result.Items[i]
+			item.TransferID = id
+			if seen[id] {
+				result.fail(item, "调动记录重复出现")
+				continue
+			}
+			seen[id] = true
+
+			transfer, message, err := approveTransfer(tx, id, 0, approver, req.Status == models.TransferStatusApproved, req.Comment, now)
+			var reqErr *requestError
+			if errors.As(err, &reqErr) {
+				result.fail(item, reqErr.Message)
+				continue
 			}
-			var newDept models.Department
-			if err := tx.First(&newDept, *transfer.ToDeptID).Error; err != nil {
-				return fmt.Errorf("目标部门不存在")
+			if err != nil {
+				return err
 			}
-			employee.Department = newDept.Name // 更新部门
-		} else if transfer.Type == models.TransferTypeRetirement {
-			// 离退休：更新状态为退休(6)
-			employee.Status = 6
+			item.EmployeeID = transfer.EmployeeID
+			item.Status = transfer.Status
+			item.Message = message
+		}
+		if result.Failed > 0 {
+			return errBatchRejected
 		}
+		return nil
+	})
+	batchResponse(c, result, err, "批量审批失败")
+}
 
-		// 保存员工变更
-		if err := tx.Save(&employee).Error; err != nil {
-			return err
+// batchResponse 批量操作的响应：存在失败的记录时返回 400 及每一条的结果
+func batchResponse(c *gin.Context, result *BatchResult, err error, failMessage string) {
+	switch {
+	case errors.Is(err, errBatchRejected):
+		for i := range result.Items {
+			if item := &result.Items[i]; item.Error == "" {
+				item.Status = 0
+				item.Message = "校验通过，因整批回滚未执行"
+			}
 		}
+		c.JSON(http.StatusOK, Response{
+			Code:    400,
+			Message: fmt.Sprintf("%d 条记录处理失败，整批未提交", result.Failed),
+			Data:    result,
+		})
+	case err != nil:
+		errorResponse(c, 500, failMessage+": "+err.Error())
+	default:
+		result.Committed = true
+		success(c, result)
+	}
+}
 
-		return nil
+// RunScheduledTransfers 立即处理所有已到生效日期的已批准调动 (管理员手动触发)
+func (tc *TransferController) RunScheduledTransfers(c *gin.Context) {
+	result, err := service.RunDueTransfers(requestDB(c), time.Now())
+	if err != nil {
+		errorResponse(c, 500, err.Error())
+		return
+	}
+	success(c, result)
+}
+
+// WithdrawTransfer 提交人撤回尚在审批中的申请
+func (tc *TransferController) WithdrawTransfer(c *gin.Context) {
+	var req CancelTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		errorResponse(c, 400, "参数错误")
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		errorResponse(c, 401, "未认证")
+		return
+	}
+	expected, err := ifMatchVersion(c)
+	if err != nil {
+		errorResponse(c, 400, err.Error())
+		return
+	}
+
+	db := requestDB(c)
+	var transfer models.Transfer
+	if err := db.First(&transfer, c.Param("id")).Error; err != nil {
+		errorResponse(c, 404, "调动记录不存在")
+		return
+	}
+	if err := checkVersion(expected, transfer.Version); err != nil {
+		versionConflict(c, err, &models.Transfer{}, transfer.ID)
+		return
+	}
+	if transfer.SubmitterID != userID {
+		errorResponse(c, 403, "只能撤回自己提交的申请")
+		return
+	}
+	if transfer.Status != models.TransferStatusPending {
+		errorResponse(c, 400, "审批已结束的申请无法撤回")
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return service.CloseTransfer(tx, &transfer, []int{models.TransferStatusPending},
+			models.TransferStatusWithdrawn, userID, req.Reason, time.Now())
 	})
+	if err != nil {
+		cancelErrorResponse(c, err, transfer.ID)
+		return
+	}
+	setETag(c, transfer.Version)
+	success(c, transfer)
+}
 
+// CancelTransfer 人事取消尚未生效的调动 (待审批或已批准未到生效日期)
+func (tc *TransferController) CancelTransfer(c *gin.Context) {
+	var req CancelTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Reason == "" {
+		errorResponse(c, 400, "请填写取消原因")
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		errorResponse(c, 401, "未认证")
+		return
+	}
+	expected, err := ifMatchVersion(c)
 	if err != nil {
-		errorResponse(c, 500, "审批处理失败: "+err.Error())
+		errorResponse(c, 400, err.Error())
+		return
+	}
+
+	db := requestDB(c)
+	var transfer models.Transfer
+	if err := db.First(&transfer, c.Param("id")).Error; err != nil {
+		errorResponse(c, 404, "调动记录不存在")
+		return
+	}
+	if err := checkVersion(expected, transfer.Version); err != nil {
+		versionConflict(c, err, &models.Transfer{}, transfer.ID)
+		return
+	}
+	switch transfer.Status {
+	case models.TransferStatusPending, models.TransferStatusApproved:
+	case models.TransferStatusCompleted:
+		errorResponse(c, 400, "调动已生效，请使用撤销")
+		return
+	default:
+		errorResponse(c, 400, "该调动已结束，无法取消")
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return service.CloseTransfer(tx, &transfer, []int{models.TransferStatusPending, models.TransferStatusApproved},
+			models.TransferStatusCancelled, userID, req.Reason, time.Now())
+	})
+	if err != nil {
+		cancelErrorResponse(c, err, transfer.ID)
+		return
+	}
+	setETag(c, transfer.Version)
+	success(c, transfer)
+}
+
+// RevertTransfer 撤销已生效的调动：在同一事务中生成反向调动并恢复员工原部门/职位/状态
+func (tc *TransferController) RevertTransfer(c *gin.Context) {
+	var req CancelTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Reason == "" {
+		errorResponse(c, 400, "请填写撤销原因")
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		errorResponse(c, 401, "未认证")
+		return
+	}
+	expected, err := ifMatchVersion(c)
+	if err != nil {
+		errorResponse(c, 400, err.Error())
+		return
+	}
+
+	db := requestDB(c)
+	var transfer models.Transfer
+	if err := db.First(&transfer, c.Param("id")).Error; err != nil {
+		errorResponse(c, 404, "调动记录不存在")
+		return
+	}
+	if err := checkVersion(expected, transfer.Version); err != nil {
+		versionConflict(c, err, &models.Transfer{}, transfer.ID)
 		return
 	}
+	if transfer.Status != models.TransferStatusCompleted {
+		errorResponse(c, 400, "只能撤销已生效的调动")
+		return
+	}
+	if transfer.RevertOfID != nil {
+		errorResponse(c, 400, "反向调动不能再次撤销，请重新提交调动申请")
+		return
+	}
+
+	var revert *models.Transfer
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		revert, err = service.RevertTransfer(tx, &transfer, userID, req.Reason, time.Now())
+		return err
+	})
+	if err != nil {
+		cancelErrorResponse(c, err, transfer.ID)
+		return
+	}
+	setETag(c, transfer.Version)
+	success(c, gin.H{"transfer": transfer, "revert": revert})
+}
+
+// cancelErrorResponse 撤回/取消/撤销失败时的错误响应，与并发的审批或修改冲突时返回 409
+func cancelErrorResponse(c *gin.Context, err error, transferID uint) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusOK, Response{
+			Code:    400,
+			Message: validationErr.Error(),
+			Data:    gin.H{"errors": validationErr.Errors},
+		})
+	case errors.Is(err, service.ErrVersionConflict),
+		errors.Is(err, service.ErrStatusChanged):
+		conflictResponse(c, http.StatusConflict, err.Error(), &models.Transfer{}, transferID)
+	case errors.Is(err, service.ErrRevertConflict),
+		errors.Is(err, service.ErrRevertUnknown):
+		errorResponse(c, 400, err.Error())
+	default:
+		errorResponse(c, 500, "操作失败: "+err.Error())
+	}
+}
 
-	success(c, gin.H{"message": "审批完成，员工信息已同步更新"})
+// orderedSteps 审批步骤按顺序预加载
+func orderedSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("step_no")
 }
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type UserController struct{}
+
+// GetUsers 获取用户列表
+func (uc *UserController) GetUsers(c *gin.Context) {
+	db := database.GetDB()
+	var users []models.User
+	if err := db.Order("id").Find(&users).Error; err != nil {
+		errorResponse(c, 500, "获取用户列表失败")
+		return
+	}
+	success(c, users)
+}
+
+// UpdateUserRole 修改用户角色
+func (uc *UserController) UpdateUserRole(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errorResponse(c, 400, "无效的用户ID")
+		return
+	}
+
+	var req models.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, 400, "请求参数错误")
+		return
+	}
+	if !models.IsValidRole(req.Role) {
+		errorResponse(c, 400, "无效的角色")
+		return
+	}
+
+	db := requestDB(c)
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		errorResponse(c, 404, "用户不存在")
+		return
+	}
+
+	// 至少保留一名管理员，避免系统无人可管理
+	if user.Role == models.RoleAdmin && req.Role != models.RoleAdmin {
+		var adminCount int64
+		db.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&adminCount)
+		if adminCount <= 1 {
+			errorResponse(c, 400, "系统至少需要保留一名管理员")
+			return
+		}
+	}
+
+	before := user
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("role", req.Role).Error; err != nil {
+			return err
+		}
+		return audit.Updated(tx, models.AuditEntityUser, user.ID, before, user)
+	})
+	if err != nil {
+		errorResponse(c, 500, "修改角色失败")
+		return
+	}
+
+	success(c, user)
+}
+
+// UpdateUserEmployee 关联/解除关联员工档案，关联后该用户可作为部门主管参与审批
+func (uc *UserController) UpdateUserEmployee(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errorResponse(c, 400, "无效的用户ID")
+		return
+	}
+
+	var req models.UpdateUserEmployeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, 400, "请求参数错误")
+		return
+	}
+
+	db := requestDB(c)
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		errorResponse(c, 404, "用户不存在")
+		return
+	}
+
+	var employeeID *uint
+	if req.EmployeeID != 0 {
+		if msg := checkEmployeeLink(db, req.EmployeeID, user.ID); msg != "" {
+			errorResponse(c, 400, msg)
+			return
+		}
+		employeeID = &req.EmployeeID
+	}
+
+	before := user
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("employee_id", employeeID).Error; err != nil {
+			return err
+		}
+		user.EmployeeID = employeeID
+		return audit.Updated(tx, models.AuditEntityUser, user.ID, before, user)
+	})
+	if err != nil {
+		errorResponse(c, 500, "关联员工失败")
+		return
+	}
+
+	success(c, user)
+}
+
+// checkEmployeeLink 校验员工存在且未被其他账号关联，返回错误提示，校验通过返回空字符串
+func checkEmployeeLink(db *gorm.DB, employeeID, userID uint) string {
+	var count int64
+	db.Model(&models.Employee{}).Where("id = ?", employeeID).Count(&count)
+	if count == 0 {
+		return "员工不存在"
+	}
+	db.Model(&models.User{}).Where("employee_id = ? AND id <> ?", employeeID, userID).Count(&count)
+	if count > 0 {
+		return "该员工已关联其他账号"
+	}
+	return ""
+}
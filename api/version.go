@@ -0,0 +1,80 @@
+// api/version.go
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/service"
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidIfMatch = errors.New(`If-Match 格式错误，应为记录的 ETag，如 "3"`)
+
+// errPreconditionFailed If-Match 中的版本号与记录当前的版本号不一致，仍属于 ErrVersionConflict
+var errPreconditionFailed = fmt.Errorf("%w", service.ErrVersionConflict)
+
+// etag 记录版本号对应的 ETag，如 "3"
+func etag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// setETag 在响应头中返回记录当前的版本号，客户端修改时通过 If-Match 带回
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", etag(version))
+}
+
+// ifMatchVersion 解析 If-Match 请求头中客户端读取时的版本号。
+// 未提供或为 * 时返回 0，表示不校验版本 (仍由事务内的条件更新防止并发覆盖)
+func ifMatchVersion(c *gin.Context) (uint, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+	if len(value) < 3 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.ParseUint(value[1:len(value)-1], 10, 32)
+	if err != nil || version == 0 {
+		return 0, errInvalidIfMatch
+	}
+	return uint(version), nil
+}
+
+// checkVersion 客户端通过 If-Match 指定了版本号时，要求与记录当前的版本号一致
+func checkVersion(expected, current uint) error {
+	if expected != 0 && expected != current {
+		return errPreconditionFailed
+	}
+	return nil
+}
+
+// versionConflict 版本冲突时的错误响应：If-Match 与记录当前版本不一致时返回 412，
+// 提交时记录已被其他人修改时返回 409
+func versionConflict(c *gin.Context, err error, model interface{}, id uint) {
+	status := http.StatusConflict
+	if errors.Is(err, errPreconditionFailed) {
+		status = http.StatusPreconditionFailed
+	}
+	conflictResponse(c, status, err.Error(), model, id)
+}
+
+// conflictResponse 与 errorResponse 不同，冲突时使用真实的 HTTP 状态码，便于客户端和代理识别；
+// model 不为 nil 时在 ETag 中返回记录当前的版本号，客户端可据此重新读取后重试
+func conflictResponse(c *gin.Context, status int, message string, model interface{}, id uint) {
+	if model != nil {
+		var versions []uint
+		if err := database.GetDB().Unscoped().Model(model).Where("id = ?", id).Pluck("version", &versions).Error; err == nil && len(versions) == 1 {
+			setETag(c, versions[0])
+		}
+	}
+	c.JSON(status, Response{
+		Code:    status,
+		Message: message,
+		Data:    nil,
+	})
+}
@@ -0,0 +1,176 @@
+// audit/audit.go
+package audit
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+)
+
+// Actor 操作者信息，随请求上下文传递到数据库操作
+type Actor struct {
+	UserID    uint
+	Username  string
+	RequestID string
+	Source    string
+}
+
+type actorKey struct{}
+
+// WithActor 在上下文中记录操作者
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom 取出上下文中的操作者，未设置时视为系统操作
+func ActorFrom(ctx context.Context) Actor {
+	if ctx != nil {
+		if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+			return actor
+		}
+	}
+	return Actor{Source: models.AuditSourceSystem}
+}
+
+// Record 写入一条审计日志，需与被审计的修改使用同一个事务。
+// 操作者从 tx 的上下文中获取 (db.WithContext)。
+func Record(tx *gorm.DB, entity string, entityID uint, action string, changes models.AuditChanges) error {
+	return record(tx, models.AuditLog{Entity: entity, EntityID: entityID, Action: action, Changes: changes})
+}
+
+// record 补全操作者信息后写入
+func record(tx *gorm.DB, entry models.AuditLog) error {
+	actor := ActorFrom(tx.Statement.Context)
+	entry.ActorID = actor.UserID
+	entry.ActorName = actor.Username
+	entry.Source = actor.Source
+	entry.RequestID = actor.RequestID
+	entry.CreatedAt = time.Now()
+	return tx.Session(&gorm.Session{NewDB: true}).Create(&entry).Error
+}
+
+// Created 记录新建，after 为新建后的模型
+func Created(tx *gorm.DB, entity string, entityID uint, after interface{}) error {
+	return Record(tx, entity, entityID, models.AuditActionCreate, Diff(nil, after))
+}
+
+// Updated 记录修改，只保存发生变化的字段，没有变化时不记录
+func Updated(tx *gorm.DB, entity string, entityID uint, before, after interface{}) error {
+	changes := Diff(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+	return Record(tx, entity, entityID, models.AuditActionUpdate, changes)
+}
+
+// TransferApplied 记录调动生效对员工档案的修改，并关联调动ID。
+// 按时间点还原员工信息时，这类修改以调动的生效日期为准，而不是写入时间。
+func TransferApplied(tx *gorm.DB, transferID, employeeID uint, before, after interface{}) error {
+	changes := Diff(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+	return record(tx, models.AuditLog{
+		Entity:     models.AuditEntityEmployee,
+		EntityID:   employeeID,
+		Action:     models.AuditActionUpdate,
+		TransferID: &transferID,
+		Changes:    changes,
+	})
+}
+
+// Deleted 记录删除，before 为删除前的模型
+func Deleted(tx *gorm.DB, entity string, entityID uint, before interface{}) error {
+	return Record(tx, entity, entityID, models.AuditActionDelete, Diff(before, nil))
+}
+
+// Diff 逐字段比较两个模型 (同一类型的结构体或其指针，可为 nil)，
+// 以 json 字段名为键返回变化的字段。关联对象、json:"-" 字段 (如密码) 以及
+// created_at / updated_at / version 不参与比较。
+func Diff(before, after interface{}) models.AuditChanges {
+	oldFields := fieldValues(before)
+	newFields := fieldValues(after)
+
+	changes := models.AuditChanges{}
+	for name, newValue := range newFields {
+		oldValue := oldFields[name]
+		if !equalValue(oldValue, newValue) {
+			changes[name] = models.AuditChange{Old: oldValue, New: newValue}
+		}
+	}
+	for name, oldValue := range oldFields {
+		if _, ok := newFields[name]; !ok && oldValue != nil {
+			changes[name] = models.AuditChange{Old: oldValue, New: nil}
+		}
+	}
+	return changes
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldValues 取出结构体中参与审计的字段值
+func fieldValues(model interface{}) map[string]interface{} {
+	if model == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(model)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	values := make(map[string]interface{})
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || name == "created_at" || name == "updated_at" || name == "version" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		// 跳过关联对象
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Slice || (ft.Kind() == reflect.Struct && ft != timeType) {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				values[name] = nil
+				continue
+			}
+			fv = fv.Elem()
+		}
+		values[name] = fv.Interface()
+	}
+	return values
+}
+
+// equalValue 比较字段值，时间按时刻比较
+func equalValue(a, b interface{}) bool {
+	ta, okA := a.(time.Time)
+	tb, okB := b.(time.Time)
+	if okA && okB {
+		return ta.Equal(tb)
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,233 @@
+// backup/backup.go
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// FormatVersion 备份文件格式版本，格式不兼容地变化时递增
+const FormatVersion = 1
+
+// ManifestFile 备份包中清单文件的名称，总是第一个条目
+const ManifestFile = "manifest.json"
+
+// tables 备份包含的全部表，按恢复顺序排列。
+// 新增模型时必须在此登记，否则备份会遗漏该表。
+// backup_runs 记录的是本机备份目录中的文件，不属于业务数据，不备份也不会被恢复覆盖。
+var tables = []interface{}{
+	&models.Department{},
+	&models.Employee{},
+	&models.User{},
+	&models.Transfer{},
+	&models.TransferApprovalStep{},
+	&models.AuditLog{},
+}
+
+// Manifest 备份清单，记录格式版本、表结构版本以及每张表的行数和校验和
+type Manifest struct {
+	FormatVersion int          `json:"format_version"`
+	SchemaVersion int          `json:"schema_version"` // 备份时数据库的迁移版本
+	Driver        string       `json:"driver"`         // 备份来源的数据库类型
+	CreatedAt     time.Time    `json:"created_at"`
+	Tables        []TableEntry `json:"tables"`
+}
+
+// TableEntry 一张表在备份包中的文件
+type TableEntry struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`   // 每行一条记录的 JSON (JSON Lines)
+	Rows   int64  `json:"rows"`   // 记录数
+	SHA256 string `json:"sha256"` // 文件内容的 SHA-256
+}
+
+// Table 按表名查找清单中的条目
+func (m *Manifest) Table(name string) (TableEntry, bool) {
+	for _, t := range m.Tables {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return TableEntry{}, false
+}
+
+// tableFile 表在备份包中的文件路径
+func tableFile(name string) string {
+	return "tables/" + name + ".jsonl"
+}
+
+// parseModel 解析模型的表结构 (表名和字段)
+func parseModel(db *gorm.DB, model interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("解析模型 %T 失败: %v", model, err)
+	}
+	return stmt.Schema, nil
+}
+
+// columns 需要备份的字段 (有对应数据库列的字段，不含关联)
+func columns(s *schema.Schema) []*schema.Field {
+	var fields []*schema.Field
+	for _, f := range s.Fields {
+		if f.DBName != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Create 把所有表导出为 tar.gz 备份包写入 w。
+// 每张表导出为 tables/<表名>.jsonl，按列名记录每个字段 (包括软删除的记录和密码哈希)，
+// 清单 manifest.json 放在最前面，恢复时可以先校验版本再读取数据。
+// 所有表在同一个只读事务中读取，保证备份内容前后一致。配置了 enc 时整个备份包加密。
+func Create(db *gorm.DB, w io.Writer, enc Encryption) (*Manifest, error) {
+	version, err := database.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{
+		FormatVersion: FormatVersion,
+		SchemaVersion: version,
+		Driver:        db.Dialector.Name(),
+		CreatedAt:     time.Now(),
+	}
+
+	// 表数据先写入临时文件，得到大小和校验和后再打包
+	files := make([]*os.File, 0, len(tables))
+	defer func() {
+		for _, f := range files {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	var opts []*sql.TxOptions
+	if db.Dialector.Name() != database.DriverSQLite {
+		opts = append(opts, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range tables {
+			f, err := os.CreateTemp("", "hrms-backup-*.jsonl")
+			if err != nil {
+				return fmt.Errorf("创建临时文件失败: %v", err)
+			}
+			files = append(files, f)
+
+			entry, err := dumpTable(tx, model, f)
+			if err != nil {
+				return err
+			}
+			manifest.Tables = append(manifest.Tables, entry)
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ew, err := enc.EncryptWriter(w)
+	if err != nil {
+		return nil, err
+	}
+	gz := gzip.NewWriter(ew)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, ManifestFile, int64(len(data)), manifest.CreatedAt, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	for i, f := range files {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := writeEntry(tw, manifest.Tables[i].File, info.Size(), manifest.CreatedAt, f); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("写入备份失败: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("写入备份失败: %v", err)
+	}
+	if err := ew.Close(); err != nil {
+		return nil, fmt.Errorf("写入备份失败: %v", err)
+	}
+	return manifest, nil
+}
+
+// dumpTable 把一张表的全部记录按 JSON Lines 写入 w
+func dumpTable(tx *gorm.DB, model interface{}, w io.Writer) (TableEntry, error) {
+	s, err := parseModel(tx, model)
+	if err != nil {
+		return TableEntry{}, err
+	}
+	entry := TableEntry{Name: s.Table, File: tableFile(s.Table)}
+	fields := columns(s)
+
+	hash := sha256.New()
+	enc := json.NewEncoder(io.MultiWriter(w, hash))
+	ctx := tx.Statement.Context
+
+	batch := reflect.New(reflect.SliceOf(s.ModelType)).Interface()
+	result := tx.Unscoped().Model(model).FindInBatches(batch, 500, func(batchTx *gorm.DB, _ int) error {
+		rows := reflect.ValueOf(batch).Elem()
+		for i := 0; i < rows.Len(); i++ {
+			row := make(map[string]interface{}, len(fields))
+			for _, f := range fields {
+				row[f.DBName] = f.ReflectValueOf(ctx, rows.Index(i)).Interface()
+			}
+			if err := enc.Encode(row); err != nil {
+				return fmt.Errorf("写入表 %s 失败: %v", s.Table, err)
+			}
+			entry.Rows++
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return entry, fmt.Errorf("导出表 %s 失败: %v", s.Table, result.Error)
+	}
+
+	entry.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return entry, nil
+}
+
+// writeEntry 向 tar 包写入一个文件
+func writeEntry(tw *tar.Writer, name string, size int64, modTime time.Time, r io.Reader) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    size,
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("写入备份失败: %v", err)
+	}
+	if _, err := io.Copy(tw, r); err != nil {
+		return fmt.Errorf("写入备份 %s 失败: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// backup/backup_test.go
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+)
+
+// 测试使用临时目录中的 SQLite 数据库，执行全部迁移后写入少量数据
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "hrms-backup-test-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	log.SetOutput(io.Discard)
+	cfg := database.GetDefaultConfig()
+	cfg.Driver = database.DriverSQLite
+	cfg.Path = filepath.Join(dir, "test.db")
+	db, err := database.Init(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := database.MigrateUp(); err != nil {
+		log.Fatal(err)
+	}
+	log.SetOutput(os.Stderr)
+
+	dept := models.Department{DeptNo: "D001", Name: "研发部"}
+	if err := db.Create(&dept).Error; err != nil {
+		log.Fatal(err)
+	}
+	employee := models.Employee{EmployeeID: "E001", Name: "张三", Status: int(models.StatusActive),
+		ArrivalDate: "2020-03-01", DepartmentID: &dept.ID}
+	if err := db.Create(&employee).Error; err != nil {
+		log.Fatal(err)
+	}
+	return m.Run()
+}
+
+func TestCreateVerifyRestore(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "backup.key")
+	key, _, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, []byte(key), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		enc  Encryption
+	}{
+		{"未加密", Encryption{}},
+		{"口令加密", Encryption{Passphrase: "correct horse battery"}},
+		{"密钥文件加密", Encryption{KeyFile: keyFile}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := database.GetDB()
+
+			var buf bytes.Buffer
+			manifest, err := Create(db, &buf, tt.enc)
+			if err != nil {
+				t.Fatalf("创建备份失败: %v", err)
+			}
+			if entry, ok := manifest.Table("employees"); !ok || entry.Rows != 1 {
+				t.Fatalf("清单中 employees = %+v, %v，期望 1 行", entry, ok)
+			}
+			archive := buf.Bytes()
+
+			if encrypted := bytes.HasPrefix(archive, ageMagic); encrypted != tt.enc.Enabled() {
+				t.Fatalf("备份是否加密 = %v，期望 %v", encrypted, tt.enc.Enabled())
+			}
+			if tt.enc.Enabled() {
+				var aerr *ArchiveError
+				if _, err := Verify(db, bytes.NewReader(archive), Encryption{}); !errors.As(err, &aerr) {
+					t.Errorf("未提供口令校验加密备份: 期望 *ArchiveError，得到 %v", err)
+				}
+				if _, err := Verify(db, bytes.NewReader(archive), Encryption{Passphrase: "wrong passphrase"}); !errors.As(err, &aerr) {
+					t.Errorf("错误口令校验加密备份: 期望 *ArchiveError，得到 %v", err)
+				}
+			}
+			if _, err := Verify(db, bytes.NewReader(archive), tt.enc); err != nil {
+				t.Fatalf("校验备份失败: %v", err)
+			}
+
+			// 非覆盖恢复要求空库
+			if _, err := Restore(db, bytes.NewReader(archive), false, tt.enc); !errors.Is(err, ErrDatabaseNotEmpty) {
+				t.Fatalf("期望 ErrDatabaseNotEmpty，得到 %v", err)
+			}
+
+			// 备份之后的修改在覆盖恢复后全部还原
+			if err := db.Model(&models.Department{}).Where("dept_no = ?", "D001").Update("name", "已改名").Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Unscoped().Where("employee_id = ?", "E001").Delete(&models.Employee{}).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Create(&models.Employee{EmployeeID: "E002", Name: "李四", Status: int(models.StatusActive), ArrivalDate: "2021-01-01"}).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Restore(db, bytes.NewReader(archive), true, tt.enc); err != nil {
+				t.Fatalf("恢复备份失败: %v", err)
+			}
+
+			var dept models.Department
+			if err := db.Where("dept_no = ?", "D001").First(&dept).Error; err != nil || dept.Name != "研发部" {
+				t.Errorf("部门 = %q, %v，期望 研发部", dept.Name, err)
+			}
+			var employees []models.Employee
+			if err := db.Order("employee_id").Find(&employees).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(employees) != 1 || employees[0].EmployeeID != "E001" || employees[0].Name != "张三" ||
+				employees[0].DepartmentID == nil || *employees[0].DepartmentID != dept.ID {
+				t.Errorf("恢复后的员工 = %+v，期望只有 E001 张三", employees)
+			}
+		})
+	}
+}
+
+// 被篡改的备份在校验时发现，恢复不会写入任何数据
+func TestVerifyRejectsTamperedArchive(t *testing.T) {
+	db := database.GetDB()
+	var buf bytes.Buffer
+	if _, err := Create(db, &buf, Encryption{}); err != nil {
+		t.Fatal(err)
+	}
+	archive := buf.Bytes()
+	archive[len(archive)/2] ^= 0xff
+
+	if _, err := Verify(db, bytes.NewReader(archive), Encryption{}); err == nil {
+		t.Error("篡改后的备份应校验失败")
+	}
+	if _, err := Restore(db, bytes.NewReader(archive), true, Encryption{}); err == nil {
+		t.Error("篡改后的备份应拒绝恢复")
+	}
+	var count int64
+	if err := db.Model(&models.Employee{}).Count(&count).Error; err != nil || count == 0 {
+		t.Errorf("恢复失败后员工数 = %d, %v，数据不应被清空", count, err)
+	}
+}
+
+// 中断的备份遗留的临时文件在下一次备份时删除
+func TestRunToDirRemovesStaleTemp(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, ".hrms_backup-123.tmp")
+	if err := os.WriteFile(stale, []byte("partial"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	run, err := RunToDir(database.GetDB(), dir, models.BackupTriggerManual, Encryption{})
+	if err != nil {
+		t.Fatalf("备份失败: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("遗留的临时文件未删除: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, run.FileName)); err != nil {
+		t.Errorf("备份文件不存在: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+// backup/encrypt.go
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"filippo.io/age"
+)
+
+// EncryptedExt 加密备份文件的扩展名后缀 (age 格式)
+const EncryptedExt = ".age"
+
+// age 加密文件的文件头
+var ageMagic = []byte("age-encryption.org/")
+
+// Encryption 备份加密方式，口令与密钥文件二选一，都为空表示不加密。
+// 加密使用 age 格式 (X25519 / scrypt + ChaCha20-Poly1305)，可以用 age 命令行工具解密。
+//   - Passphrase: 口令，经 scrypt 派生密钥，加密和恢复使用同一口令
+//   - KeyFile: age 密钥文件。包含私钥 (AGE-SECRET-KEY-...) 时可加密也可恢复；
+//     只包含公钥 (age1...) 时只能加密，恢复需另外提供私钥文件，适合私钥离线保管
+type Encryption struct {
+	Passphrase string `yaml:"passphrase"`
+	KeyFile    string `yaml:"key_file"`
+}
+
+// Enabled 是否配置了加密
+func (e Encryption) Enabled() bool {
+	return e.Passphrase != "" || e.KeyFile != ""
+}
+
+// 加密口令的最小长度
+const minPassphraseLen = 12
+
+// Validate 检查加密配置，密钥文件需能读取并解析
+func (e Encryption) Validate() error {
+	if e.Passphrase != "" && e.KeyFile != "" {
+		return errors.New("备份加密的口令与密钥文件只能配置一个")
+	}
+	if e.Passphrase != "" && len([]rune(e.Passphrase)) < minPassphraseLen {
+		return fmt.Errorf("备份加密口令至少需要 %d 个字符", minPassphraseLen)
+	}
+	if e.KeyFile != "" {
+		if _, err := e.recipients(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recipients 加密使用的接收方
+func (e Encryption) recipients() ([]age.Recipient, error) {
+	if e.Passphrase != "" {
+		r, err := age.NewScryptRecipient(e.Passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("备份加密口令无效: %v", err)
+		}
+		return []age.Recipient{r}, nil
+	}
+
+	data, err := os.ReadFile(e.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取备份密钥文件失败: %v", err)
+	}
+	// 私钥文件：由私钥推出公钥
+	if ids, err := age.ParseIdentities(bytes.NewReader(data)); err == nil {
+		var recipients []age.Recipient
+		for _, id := range ids {
+			x, ok := id.(*age.X25519Identity)
+			if !ok {
+				return nil, fmt.Errorf("备份密钥文件 %s 包含不支持的密钥类型", e.KeyFile)
+			}
+			recipients = append(recipients, x.Recipient())
+		}
+		return recipients, nil
+	}
+	recipients, err := age.ParseRecipients(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("备份密钥文件 %s 既不是 age 私钥也不是公钥: %v", e.KeyFile, err)
+	}
+	return recipients, nil
+}
+
+// identities 解密使用的私钥
+func (e Encryption) identities() ([]age.Identity, error) {
+	if e.Passphrase != "" {
+		id, err := age.NewScryptIdentity(e.Passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("备份加密口令无效: %v", err)
+		}
+		return []age.Identity{id}, nil
+	}
+
+	file, err := os.Open(e.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取备份密钥文件失败: %v", err)
+	}
+	defer file.Close()
+	ids, err := age.ParseIdentities(file)
+	if err != nil {
+		return nil, fmt.Errorf("备份密钥文件 %s 不包含私钥，无法解密: %v", e.KeyFile, err)
+	}
+	return ids, nil
+}
+
+// EncryptWriter 按配置包装 w，未配置加密时原样写入。返回的 Closer 必须在写完后关闭。
+func (e Encryption) EncryptWriter(w io.Writer) (io.WriteCloser, error) {
+	if !e.Enabled() {
+		return nopWriteCloser{w}, nil
+	}
+	recipients, err := e.recipients()
+	if err != nil {
+		return nil, err
+	}
+	return age.Encrypt(w, recipients...)
+}
+
+// decryptReader 识别加密的备份并解密；未加密的备份原样读取
+func (e Encryption) decryptReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(ageMagic))
+	if !bytes.Equal(head, ageMagic) {
+		return br, nil
+	}
+	if !e.Enabled() {
+		return nil, archiveErrorf("备份文件已加密，恢复需要提供口令或私钥文件")
+	}
+	ids, err := e.identities()
+	if err != nil {
+		return nil, err
+	}
+	dr, err := age.Decrypt(br, ids...)
+	if err != nil {
+		return nil, archiveErrorf("解密备份失败，口令或私钥不正确: %v", err)
+	}
+	return dr, nil
+}
+
+// FileName 按是否加密为备份文件名加上扩展名
+func (e Encryption) FileName(name string) string {
+	if e.Enabled() {
+		return name + EncryptedExt
+	}
+	return name
+}
+
+// GenerateKey 生成新的 age 密钥对，返回私钥文件内容和公钥
+func GenerateKey() (string, string, error) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		return "", "", err
+	}
+	recipient := id.Recipient().String()
+	content := fmt.Sprintf("# public key: %s\n%s\n", recipient, id.String())
+	return content, recipient, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
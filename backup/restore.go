@@ -0,0 +1,301 @@
+// backup/restore.go
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// 清单文件的大小上限
+const maxManifestSize = 1 << 20
+
+// 恢复时每批写入的记录数
+const restoreBatchSize = 500
+
+// ErrDatabaseNotEmpty 未指定覆盖时，目标数据库中已有数据
+var ErrDatabaseNotEmpty = errors.New("数据库中已有数据，如需用备份覆盖现有数据请指定覆盖恢复")
+
+// ArchiveError 备份包无效 (格式、版本或校验和不符)
+type ArchiveError struct {
+	Message string
+}
+
+func (e *ArchiveError) Error() string {
+	return e.Message
+}
+
+func archiveErrorf(format string, args ...interface{}) error {
+	return &ArchiveError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Verify 完整读取备份包并校验：格式版本、表结构版本需与当前数据库一致，
+// 每张表的行数和 SHA-256 需与清单一致，每条记录都能解析为对应的模型。
+// 加密的备份需通过 enc 提供口令或私钥。
+func Verify(db *gorm.DB, r io.Reader, enc Encryption) (*Manifest, error) {
+	return readArchive(db, r, enc, nil)
+}
+
+// Restore 校验备份包后在一个事务中把数据载入数据库，任何错误都会整体回滚。
+// replace 为 false 时要求各表均为空库；为 true 时先清空所有表再载入，
+// 恢复后数据库内容与备份完全一致 (包括用户账号和审计日志)。
+func Restore(db *gorm.DB, r io.ReadSeeker, replace bool, enc Encryption) (*Manifest, error) {
+	if _, err := Verify(db, r, enc); err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var manifest *Manifest
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range tables {
+			s, err := parseModel(tx, model)
+			if err != nil {
+				return err
+			}
+			if !replace {
+				var count int64
+				if err := tx.Unscoped().Model(model).Count(&count).Error; err != nil {
+					return fmt.Errorf("查询表 %s 失败: %v", s.Table, err)
+				}
+				if count > 0 {
+					return ErrDatabaseNotEmpty
+				}
+				continue
+			}
+			if err := tx.Exec("DELETE FROM ?", clause.Table{Name: s.Table}).Error; err != nil {
+				return fmt.Errorf("清空表 %s 失败: %v", s.Table, err)
+			}
+		}
+
+		// 按表分批写入，表切换时写入上一张表剩余的记录
+		var current *schema.Schema
+		var pending reflect.Value
+		flush := func() error {
+			if current == nil || pending.Len() == 0 {
+				return nil
+			}
+			if err := tx.Omit(clause.Associations).Create(pending.Interface()).Error; err != nil {
+				return fmt.Errorf("写入表 %s 失败: %v", current.Table, err)
+			}
+			pending = reflect.MakeSlice(pending.Type(), 0, restoreBatchSize)
+			return nil
+		}
+
+		var err error
+		manifest, err = readArchive(tx, r, enc, func(s *schema.Schema, record reflect.Value) error {
+			if current != s {
+				if err := flush(); err != nil {
+					return err
+				}
+				current = s
+				pending = reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(s.ModelType)), 0, restoreBatchSize)
+			}
+			pending = reflect.Append(pending, record)
+			if pending.Len() >= restoreBatchSize {
+				return flush()
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		return resetSequences(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// resetSequences 载入带主键的记录后，PostgreSQL 的自增序列不会随之前进，需手动调整。
+// MySQL 与 SQLite 会自动以已有的最大主键继续。
+func resetSequences(tx *gorm.DB) error {
+	if tx.Dialector.Name() != database.DriverPostgres {
+		return nil
+	}
+	for _, model := range tables {
+		s, err := parseModel(tx, model)
+		if err != nil {
+			return err
+		}
+		pk := s.PrioritizedPrimaryField.DBName
+		sql := fmt.Sprintf(`SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE(MAX(%s), 0) + 1, false) FROM %s`,
+			s.Table, pk, tx.Statement.Quote(pk), tx.Statement.Quote(s.Table))
+		if err := tx.Exec(sql).Error; err != nil {
+			return fmt.Errorf("调整表 %s 的自增序列失败: %v", s.Table, err)
+		}
+	}
+	return nil
+}
+
+// readArchive 读取并校验备份包，每解析出一条记录调用一次 each (可为 nil)。
+// 记录为指向模型的指针，字段按列名从 JSON 还原。
+func readArchive(db *gorm.DB, r io.Reader, enc Encryption, each func(s *schema.Schema, record reflect.Value) error) (*Manifest, error) {
+	r, err := enc.decryptReader(r)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, archiveErrorf("不是有效的备份文件 (tar.gz): %v", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	// 1. 清单必须是第一个文件
+	header, err := tr.Next()
+	if err != nil {
+		return nil, archiveErrorf("不是有效的备份文件 (tar.gz): %v", err)
+	}
+	if header.Name != ManifestFile {
+		return nil, archiveErrorf("备份文件缺少清单 %s", ManifestFile)
+	}
+	data, err := io.ReadAll(io.LimitReader(tr, maxManifestSize+1))
+	if err != nil {
+		return nil, archiveErrorf("读取清单失败: %v", err)
+	}
+	if len(data) > maxManifestSize {
+		return nil, archiveErrorf("清单文件过大")
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, archiveErrorf("清单格式错误: %v", err)
+	}
+
+	// 2. 校验版本，表结构不一致时无法可靠地载入
+	if manifest.FormatVersion != FormatVersion {
+		return nil, archiveErrorf("不支持的备份格式版本 %d (当前程序为 %d)", manifest.FormatVersion, FormatVersion)
+	}
+	version, err := database.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if manifest.SchemaVersion != version {
+		return nil, archiveErrorf("备份的数据库结构版本 (%d) 与当前数据库 (%d) 不一致，请先将数据库迁移到相同版本 (migrate up/down)",
+			manifest.SchemaVersion, version)
+	}
+
+	schemas := make(map[string]*schema.Schema, len(tables))
+	for _, model := range tables {
+		s, err := parseModel(db, model)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := manifest.Table(s.Table); !ok {
+			return nil, archiveErrorf("备份缺少表 %s", s.Table)
+		}
+		schemas[s.Table] = s
+	}
+	byFile := make(map[string]TableEntry, len(manifest.Tables))
+	for _, t := range manifest.Tables {
+		if schemas[t.Name] == nil {
+			return nil, archiveErrorf("备份包含未知的表 %s", t.Name)
+		}
+		if t.File != tableFile(t.Name) {
+			return nil, archiveErrorf("表 %s 的文件名 %s 不正确", t.Name, t.File)
+		}
+		byFile[t.File] = t
+	}
+
+	// 3. 逐个读取表文件，核对行数和校验和
+	seen := make(map[string]bool, len(manifest.Tables))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, archiveErrorf("读取备份失败: %v", err)
+		}
+		entry, ok := byFile[header.Name]
+		if !ok || header.Typeflag != tar.TypeReg {
+			return nil, archiveErrorf("备份包含未知的文件 %s", header.Name)
+		}
+		if seen[entry.Name] {
+			return nil, archiveErrorf("备份中表 %s 重复出现", entry.Name)
+		}
+		seen[entry.Name] = true
+
+		if err := readTable(db, schemas[entry.Name], entry, tr, each); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range manifest.Tables {
+		if !seen[t.Name] {
+			return nil, archiveErrorf("备份缺少表 %s 的数据文件 %s", t.Name, t.File)
+		}
+	}
+	return &manifest, nil
+}
+
+// readTable 读取一张表的 JSON Lines 文件
+func readTable(db *gorm.DB, s *schema.Schema, entry TableEntry, r io.Reader, each func(*schema.Schema, reflect.Value) error) error {
+	fields := make(map[string]*schema.Field)
+	for _, f := range columns(s) {
+		fields[f.DBName] = f
+	}
+	ctx := db.Statement.Context
+
+	hash := sha256.New()
+	br := bufio.NewReader(io.TeeReader(r, hash))
+	var rows int64
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			rows++
+			var row map[string]json.RawMessage
+			if err := json.Unmarshal(line, &row); err != nil {
+				return archiveErrorf("表 %s 第 %d 行格式错误: %v", entry.Name, rows, err)
+			}
+			record := reflect.New(s.ModelType)
+			for column, raw := range row {
+				f, ok := fields[column]
+				if !ok {
+					return archiveErrorf("表 %s 第 %d 行包含未知字段 %s", entry.Name, rows, column)
+				}
+				value := reflect.New(f.FieldType)
+				if err := json.Unmarshal(raw, value.Interface()); err != nil {
+					return archiveErrorf("表 %s 第 %d 行字段 %s 无效: %v", entry.Name, rows, column, err)
+				}
+				f.ReflectValueOf(ctx, record.Elem()).Set(value.Elem())
+			}
+			if each != nil {
+				if err := each(s, record); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return archiveErrorf("读取表 %s 失败: %v", entry.Name, err)
+		}
+	}
+
+	if rows != entry.Rows {
+		return archiveErrorf("表 %s 的记录数 (%d) 与清单 (%d) 不一致", entry.Name, rows, entry.Rows)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != entry.SHA256 {
+		return archiveErrorf("表 %s 的校验和不一致，备份文件可能已损坏", entry.Name)
+	}
+	return nil
+}
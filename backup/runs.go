@@ -0,0 +1,170 @@
+// backup/runs.go
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+)
+
+// Retention 备份目录的保留策略 (祖父-父-子轮换)：
+// 保留最近 Daily 天每天最新的一份、最近 Weekly 周每周最新的一份、最近 Monthly 个月每月最新的一份，
+// 三者取并集，其余备份文件删除。全部为 0 表示不删除任何备份。
+type Retention struct {
+	Daily   int `yaml:"daily"`
+	Weekly  int `yaml:"weekly"`
+	Monthly int `yaml:"monthly"`
+}
+
+// Enabled 是否配置了保留策略
+func (r Retention) Enabled() bool {
+	return r.Daily > 0 || r.Weekly > 0 || r.Monthly > 0
+}
+
+// RunToDir 创建一份完整备份写入 dir，并在 backup_runs 表中记录结果 (成功或失败都会记录)。
+// 文件先写入临时文件，完成后再改名，目录中不会出现不完整的备份。配置了 enc 时备份文件加密 (.tar.gz.age)。
+func RunToDir(db *gorm.DB, dir, trigger string, enc Encryption) (*models.BackupRun, error) {
+	run := &models.BackupRun{Trigger: trigger, StartedAt: time.Now()}
+
+	err := writeToDir(db, dir, run, enc)
+	run.FinishedAt = time.Now()
+	run.DurationMs = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+	if err != nil {
+		run.Status = models.BackupStatusFailed
+		run.Error = err.Error()
+	} else {
+		run.Status = models.BackupStatusSuccess
+	}
+
+	if createErr := db.Create(run).Error; createErr != nil {
+		return run, errors.Join(err, fmt.Errorf("记录备份结果失败: %v", createErr))
+	}
+	return run, err
+}
+
+// tmpPattern 写入中的备份临时文件名
+const tmpPattern = ".hrms_backup-*.tmp"
+
+// writeToDir 写入备份文件，填充文件名、大小和校验和。
+// 备份由调用方串行执行，开始时目录中的临时文件都是此前中断 (如进程退出) 时遗留的，先行删除
+func writeToDir(db *gorm.DB, dir string, run *models.BackupRun, enc Encryption) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("创建备份目录失败: %v", err)
+	}
+	stale, err := filepath.Glob(filepath.Join(dir, tmpPattern))
+	if err != nil {
+		return err
+	}
+	for _, p := range stale {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除遗留的临时文件失败: %v", err)
+		}
+	}
+	// 文件名精确到毫秒，避免定时备份与手动备份在同一秒内执行时重名
+	name := enc.FileName(fmt.Sprintf("hrms_backup_%s_%03d.tar.gz", run.StartedAt.Format("20060102_150405"), run.StartedAt.Nanosecond()/int(time.Millisecond)))
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("备份文件 %s 已存在", name)
+	}
+
+	tmp, err := os.CreateTemp(dir, tmpPattern)
+	if err != nil {
+		return fmt.Errorf("创建备份文件失败: %v", err)
+	}
+	defer os.Remove(tmp.Name()) // 改名成功后删除不存在的文件，忽略错误
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	_, err = Create(db, io.MultiWriter(tmp, hash, counter), enc)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("保存备份文件失败: %v", err)
+	}
+
+	run.FileName = name
+	run.Size = counter.n
+	run.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// Prune 按保留策略删除 dir 中多余的备份文件，并在记录中标记删除时间。
+// 只处理 backup_runs 中记录的成功备份，目录中的其他文件不受影响；最新的一份总是保留。
+func Prune(db *gorm.DB, dir string, keep Retention) ([]models.BackupRun, error) {
+	if !keep.Enabled() {
+		return nil, nil
+	}
+
+	var runs []models.BackupRun
+	if err := db.Where("status = ? AND pruned_at IS NULL", models.BackupStatusSuccess).
+		Order("started_at DESC, id DESC").Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("查询备份记录失败: %v", err)
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+
+	kept := map[uint]bool{runs[0].ID: true}
+	// 每个周期内保留最新的一份，直到保留了 n 个周期
+	keepLatest := func(n int, period func(time.Time) string) {
+		seen := make(map[string]bool, n)
+		for _, run := range runs {
+			if len(seen) >= n {
+				return
+			}
+			key := period(run.StartedAt.Local())
+			if !seen[key] {
+				seen[key] = true
+				kept[run.ID] = true
+			}
+		}
+	}
+	keepLatest(keep.Daily, func(t time.Time) string { return t.Format("2006-01-02") })
+	keepLatest(keep.Weekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	keepLatest(keep.Monthly, func(t time.Time) string { return t.Format("2006-01") })
+
+	var pruned []models.BackupRun
+	for _, run := range runs {
+		if kept[run.ID] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, run.FileName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return pruned, fmt.Errorf("删除备份文件 %s 失败: %v", run.FileName, err)
+		}
+		now := time.Now()
+		if err := db.Model(&run).Update("pruned_at", now).Error; err != nil {
+			return pruned, fmt.Errorf("更新备份记录失败: %v", err)
+		}
+		run.PrunedAt = &now
+		pruned = append(pruned, run)
+	}
+	return pruned, nil
+}
@@ -0,0 +1,196 @@
+// commands.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/backup"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/config"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+)
+
+const usage = `用法:
+  %[1]s [--config config.yaml]                                  启动服务
+  %[1]s [--config config.yaml] migrate up                       执行所有未执行的迁移
+  %[1]s [--config config.yaml] migrate down                     回滚最近一次迁移
+  %[1]s [--config config.yaml] migrate status                   查看迁移状态
+  %[1]s [--config config.yaml] backup create [-o 文件]          导出完整备份 (tar.gz)
+  %[1]s [--config config.yaml] backup verify 文件               校验备份文件
+  %[1]s [--config config.yaml] backup restore [--replace] 文件  从备份恢复，--replace 覆盖现有数据
+  %[1]s [--config config.yaml] backup keygen -o 文件            生成备份加密用的 age 私钥
+
+  backup create / verify / restore 可用 --key-file 或 --passphrase-file 覆盖 backup.encryption 配置
+
+参数:
+`
+
+// runCommand 执行命令行子命令
+func runCommand(cfg config.Config, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(args[1:])
+	case "backup":
+		return runBackup(cfg.Backup.Encryption, args[1:])
+	default:
+		return fmt.Errorf("未知命令: %s", args[0])
+	}
+}
+
+// runMigrate 处理 migrate up|down|status
+func runMigrate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("用法: migrate up|down|status")
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := database.MigrateUp()
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("数据库已是最新版本")
+		}
+		return nil
+	case "down":
+		m, err := database.MigrateDown()
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			fmt.Println("没有可回滚的迁移")
+		}
+		return nil
+	case "status":
+		states, err := database.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range states {
+			status, appliedAt := "pending", "-"
+			if s.Applied {
+				status, appliedAt = "applied", s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("未知的 migrate 操作: %s (可选 up|down|status)", args[0])
+	}
+}
+
+// runBackup 处理 backup create|verify|restore|keygen，enc 为配置的备份加密方式
+func runBackup(enc backup.Encryption, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: backup create|verify|restore|keygen")
+	}
+
+	fs := flag.NewFlagSet("backup "+args[0], flag.ContinueOnError)
+	output := fs.String("o", "", "输出文件路径 (默认 hrms_backup_<时间>.tar.gz)")
+	replace := fs.Bool("replace", false, "清空现有数据后恢复")
+	keyFile := fs.String("key-file", "", "age 密钥文件，覆盖 backup.encryption 配置")
+	passphraseFile := fs.String("passphrase-file", "", "从文件读取加密口令，覆盖 backup.encryption 配置")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	switch {
+	case *keyFile != "" && *passphraseFile != "":
+		return fmt.Errorf("--key-file 与 --passphrase-file 只能指定一个")
+	case *keyFile != "":
+		enc = backup.Encryption{KeyFile: *keyFile}
+	case *passphraseFile != "":
+		data, err := os.ReadFile(*passphraseFile)
+		if err != nil {
+			return fmt.Errorf("读取口令文件失败: %v", err)
+		}
+		enc = backup.Encryption{Passphrase: strings.TrimRight(string(data), "\r\n")}
+	}
+	if err := enc.Validate(); err != nil {
+		return err
+	}
+	db := database.GetDB()
+
+	switch args[0] {
+	case "keygen":
+		if *output == "" {
+			return fmt.Errorf("用法: backup keygen -o 私钥文件")
+		}
+		content, recipient, err := backup.GenerateKey()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*output, []byte(content), 0o600); err != nil {
+			return fmt.Errorf("写入密钥文件失败: %v", err)
+		}
+		fmt.Printf("已生成私钥 %s，请妥善保管 (丢失后无法恢复加密的备份)\n公钥: %s\n", *output, recipient)
+		return nil
+	case "create":
+		path := *output
+		if path == "" {
+			path = enc.FileName(fmt.Sprintf("hrms_backup_%s.tar.gz", time.Now().Format("20060102150405")))
+		}
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return fmt.Errorf("创建备份文件失败: %v", err)
+		}
+		manifest, err := backup.Create(db, file, enc)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+			return err
+		}
+		fmt.Printf("已备份到 %s\n", path)
+		return printManifest(manifest)
+	case "verify", "restore":
+		if fs.NArg() != 1 {
+			return fmt.Errorf("用法: backup %s 文件", args[0])
+		}
+		file, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return fmt.Errorf("打开备份文件失败: %v", err)
+		}
+		defer file.Close()
+
+		var manifest *backup.Manifest
+		if args[0] == "verify" {
+			manifest, err = backup.Verify(db, file, enc)
+		} else {
+			manifest, err = backup.Restore(db, file, *replace, enc)
+		}
+		if errors.Is(err, backup.ErrDatabaseNotEmpty) {
+			return fmt.Errorf("数据库中已有数据，如需覆盖请使用 backup restore --replace")
+		}
+		if err != nil {
+			return err
+		}
+		if args[0] == "verify" {
+			fmt.Println("备份文件校验通过")
+		} else {
+			fmt.Println("恢复完成")
+		}
+		return printManifest(manifest)
+	default:
+		return fmt.Errorf("未知的 backup 操作: %s (可选 create|verify|restore|keygen)", args[0])
+	}
+}
+
+// printManifest 打印备份清单
+func printManifest(m *backup.Manifest) error {
+	fmt.Printf("备份时间: %s  数据库结构版本: %d  来源: %s\n", m.CreatedAt.Format("2006-01-02 15:04:05"), m.SchemaVersion, m.Driver)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tROWS\tSHA256")
+	for _, t := range m.Tables {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", t.Name, t.Rows, t.SHA256)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,356 @@
+// config/config.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/backup"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix 环境变量前缀，例如 HRMS_DB_PASSWORD
+const EnvPrefix = "HRMS_"
+
+// DefaultPath 未指定 --config 时尝试读取的配置文件
+const DefaultPath = "config.yaml"
+
+// Config 应用配置
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Database  database.Config `yaml:"database"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	Admin     AdminConfig     `yaml:"admin"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Approval  ApprovalConfig  `yaml:"approval"`
+	Retention RetentionConfig `yaml:"retention"`
+	Backup    BackupConfig    `yaml:"backup"`
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	Addr        string   `yaml:"addr"`         // 监听地址，例如 :8080
+	Mode        string   `yaml:"mode"`         // gin 运行模式: debug / release / test
+	CORSOrigins []string `yaml:"cors_origins"` // 允许跨域的来源，* 表示全部
+}
+
+// JWTConfig 令牌配置
+type JWTConfig struct {
+	Secret string        `yaml:"secret"` // 签名密钥
+	Expire time.Duration `yaml:"expire"` // 有效期，例如 24h
+	Issuer string        `yaml:"issuer"` // 签发者
+}
+
+// AdminConfig 初始管理员账号，仅在系统中不存在管理员时创建
+type AdminConfig struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// SchedulerConfig 后台定时任务配置
+type SchedulerConfig struct {
+	TransferInterval time.Duration `yaml:"transfer_interval"` // 检查到期调动的间隔，0 表示不启用
+}
+
+// ApprovalConfig 调动审批配置
+type ApprovalConfig struct {
+	// Chains 调动类型 -> 依次审批的审批人类型，未配置的类型为单级"审批人"审批。
+	// 可选: from_dept_manager, to_dept_manager, hr, approver, admin
+	Chains map[int][]string `yaml:"chains"`
+}
+
+// RetentionConfig 已删除记录的保留配置
+type RetentionConfig struct {
+	// PurgeAfter 员工、部门删除后至少保留的时长，超过后管理员才能彻底清除，
+	// 例如 8760h (一年)；0 表示不允许清除
+	PurgeAfter time.Duration `yaml:"purge_after"`
+}
+
+// BackupConfig 自动备份配置
+type BackupConfig struct {
+	// Schedule 执行完整备份的 cron 表达式 (分 时 日 月 周)，例如 "0 2 * * *" 为每天 2 点；为空表示不启用
+	Schedule string `yaml:"schedule"`
+	// Dir 备份文件保存目录
+	Dir string `yaml:"dir"`
+	// Keep 备份文件的保留策略
+	Keep backup.Retention `yaml:"keep"`
+	// Encryption 备份加密 (口令或 age 密钥文件)，同时用于员工 CSV 导出；为空表示不加密
+	Encryption backup.Encryption `yaml:"encryption"`
+	// MaxRestoreMB 通过接口上传恢复的备份文件大小上限 (MB)
+	MaxRestoreMB int `yaml:"max_restore_mb"`
+}
+
+// Default 默认配置
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:        ":8080",
+			Mode:        "debug",
+			CORSOrigins: []string{"*"},
+		},
+		Database: database.GetDefaultConfig(),
+		JWT: JWTConfig{
+			Expire: 24 * time.Hour,
+			Issuer: "hrms-api",
+		},
+		Scheduler: SchedulerConfig{
+			TransferInterval: 10 * time.Minute,
+		},
+		Backup: BackupConfig{
+			Dir:          "backups",
+			Keep:         backup.Retention{Daily: 7, Weekly: 4, Monthly: 12},
+			MaxRestoreMB: 512,
+		},
+	}
+}
+
+// Load 依次应用默认值、配置文件和环境变量，并校验结果。
+// path 为空时读取 HRMS_CONFIG 或 config.yaml（文件不存在则跳过），.toml 文件按 TOML 解析。
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	explicit := path != ""
+	if !explicit {
+		path = os.Getenv(EnvPrefix + "CONFIG")
+		explicit = path != ""
+	}
+	if path == "" {
+		path = DefaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := decode(path, data, &cfg); err != nil {
+			return cfg, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist) && !explicit:
+		// 未显式指定时允许没有配置文件，完全依赖环境变量
+	default:
+		return cfg, fmt.Errorf("读取配置文件 %s 失败: %v", path, err)
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return cfg, err
+	}
+
+	// 未配置端口时按驱动取默认值 (mysql 3306、postgres 5432)
+	if cfg.Database.Port == 0 {
+		cfg.Database.Port = database.DefaultPort(cfg.Database.Driver)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// decode 按扩展名解析配置文件：.toml 为 TOML，其余按 YAML 解析
+func decode(path string, data []byte, cfg *Config) error {
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		node, err := tomlToYAML(data)
+		if err != nil {
+			return err
+		}
+		return node.Decode(cfg)
+	}
+	return yaml.Unmarshal(data, cfg)
+}
+
+// applyEnv 使用环境变量覆盖配置项
+func applyEnv(cfg *Config) error {
+	str := func(target *string) func(string) error {
+		return func(v string) error {
+			*target = v
+			return nil
+		}
+	}
+
+	overrides := map[string]func(string) error{
+		"SERVER_ADDR": str(&cfg.Server.Addr),
+		"SERVER_MODE": str(&cfg.Server.Mode),
+		"CORS_ORIGINS": func(v string) error {
+			cfg.Server.CORSOrigins = splitList(v)
+			return nil
+		},
+		"DB_DRIVER":  str(&cfg.Database.Driver),
+		"DB_PATH":    str(&cfg.Database.Path),
+		"DB_SSLMODE": str(&cfg.Database.SSLMode),
+		"DB_HOST":    str(&cfg.Database.Host),
+		"DB_PORT": func(v string) error {
+			port, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("端口必须是数字")
+			}
+			cfg.Database.Port = port
+			return nil
+		},
+		"DB_USER":     str(&cfg.Database.User),
+		"DB_PASSWORD": str(&cfg.Database.Password),
+		"DB_NAME":     str(&cfg.Database.Name),
+		"DB_CHARSET":  str(&cfg.Database.Charset),
+		"JWT_SECRET":  str(&cfg.JWT.Secret),
+		"JWT_EXPIRE": func(v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("有效期格式错误，例如 24h")
+			}
+			cfg.JWT.Expire = d
+			return nil
+		},
+		"JWT_ISSUER":     str(&cfg.JWT.Issuer),
+		"ADMIN_USERNAME": str(&cfg.Admin.Username),
+		"ADMIN_PASSWORD": str(&cfg.Admin.Password),
+		"TRANSFER_INTERVAL": func(v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("间隔格式错误，例如 10m")
+			}
+			cfg.Scheduler.TransferInterval = d
+			return nil
+		},
+		"PURGE_AFTER": func(v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("保留期限格式错误，例如 8760h")
+			}
+			cfg.Retention.PurgeAfter = d
+			return nil
+		},
+		"BACKUP_SCHEDULE":   str(&cfg.Backup.Schedule),
+		"BACKUP_DIR":        str(&cfg.Backup.Dir),
+		"BACKUP_PASSPHRASE": str(&cfg.Backup.Encryption.Passphrase),
+		"BACKUP_KEY_FILE":   str(&cfg.Backup.Encryption.KeyFile),
+		"BACKUP_MAX_RESTORE_MB": func(v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("大小必须是数字 (MB)")
+			}
+			cfg.Backup.MaxRestoreMB = n
+			return nil
+		},
+	}
+
+	for key, set := range overrides {
+		value, ok := os.LookupEnv(EnvPrefix + key)
+		if !ok {
+			continue
+		}
+		if err := set(value); err != nil {
+			return fmt.Errorf("环境变量 %s%s 无效: %v", EnvPrefix, key, err)
+		}
+	}
+	return nil
+}
+
+// Validate 校验配置是否完整有效
+func (c Config) Validate() error {
+	var problems []string
+
+	if c.Server.Addr == "" {
+		problems = append(problems, "server.addr 不能为空")
+	}
+	switch c.Server.Mode {
+	case "debug", "release", "test":
+	default:
+		problems = append(problems, "server.mode 只能是 debug、release 或 test")
+	}
+
+	switch c.Database.Driver {
+	case database.DriverSQLite:
+		if c.Database.Path == "" {
+			problems = append(problems, "database.path 不能为空")
+		}
+	case database.DriverMySQL, database.DriverPostgres:
+		if c.Database.Host == "" {
+			problems = append(problems, "database.host 不能为空")
+		}
+		if c.Database.Port <= 0 || c.Database.Port > 65535 {
+			problems = append(problems, "database.port 超出范围")
+		}
+		if c.Database.User == "" {
+			problems = append(problems, "database.user 不能为空")
+		}
+		if c.Database.Name == "" {
+			problems = append(problems, "database.name 不能为空")
+		}
+	default:
+		problems = append(problems, "database.driver 只能是 mysql、sqlite 或 postgres")
+	}
+
+	if len(c.JWT.Secret) < 16 {
+		problems = append(problems, "jwt.secret 至少需要 16 个字符")
+	}
+	if c.JWT.Expire <= 0 {
+		problems = append(problems, "jwt.expire 必须大于 0")
+	}
+
+	if c.Scheduler.TransferInterval < 0 {
+		problems = append(problems, "scheduler.transfer_interval 不能为负数")
+	}
+
+	if c.Retention.PurgeAfter < 0 {
+		problems = append(problems, "retention.purge_after 不能为负数")
+	}
+
+	if c.Backup.Schedule != "" {
+		if _, err := cron.ParseStandard(c.Backup.Schedule); err != nil {
+			problems = append(problems, fmt.Sprintf("backup.schedule 不是有效的 cron 表达式: %v", err))
+		}
+	}
+	if c.Backup.Dir == "" {
+		problems = append(problems, "backup.dir 不能为空")
+	}
+	if c.Backup.Keep.Daily < 0 || c.Backup.Keep.Weekly < 0 || c.Backup.Keep.Monthly < 0 {
+		problems = append(problems, "backup.keep 的保留份数不能为负数")
+	}
+	if c.Backup.MaxRestoreMB <= 0 {
+		problems = append(problems, "backup.max_restore_mb 必须大于 0")
+	}
+	if err := c.Backup.Encryption.Validate(); err != nil {
+		problems = append(problems, "backup.encryption: "+err.Error())
+	}
+
+	for transferType, chain := range c.Approval.Chains {
+		if !models.IsValidTransferType(transferType) {
+			problems = append(problems, fmt.Sprintf("approval.chains 中的调动类型 %d 无效", transferType))
+			continue
+		}
+		if len(chain) == 0 {
+			problems = append(problems, fmt.Sprintf("approval.chains.%d 不能为空", transferType))
+		}
+		for _, approverType := range chain {
+			if !models.IsValidApproverType(approverType) {
+				problems = append(problems, fmt.Sprintf("approval.chains.%d 中的审批人类型 %q 无效", transferType, approverType))
+			}
+		}
+	}
+
+	if (c.Admin.Username == "") != (c.Admin.Password == "") {
+		problems = append(problems, "admin.username 与 admin.password 需同时配置")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("配置校验失败: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// splitList 拆分逗号分隔的列表
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,65 @@
+// config/toml.go
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// tomlToYAML 将 TOML 配置转换为 YAML 节点，
+// 与 YAML 配置共用字段名、时长 (例如 24h) 以及 approval.chains 的数字键等解析规则
+func tomlToYAML(data []byte) (*yaml.Node, error) {
+	var raw map[string]any
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return yamlNode(raw)
+}
+
+func yamlNode(v any) (*yaml.Node, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, k := range keys {
+			value, err := yamlNode(v[k])
+			if err != nil {
+				return nil, err
+			}
+			// 键不指定类型，由 YAML 按内容推断，数字键才能解析到 map[int]
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k}, value)
+		}
+		return node, nil
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v {
+			value, err := yamlNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, value)
+		}
+		return node, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}, nil
+	case int64:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(v, 10)}, nil
+	case float64:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: strconv.FormatFloat(v, 'g', -1, 64)}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}, nil
+	case time.Time:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: v.Format(time.RFC3339Nano)}, nil
+	default:
+		return nil, fmt.Errorf("不支持的 TOML 值类型 %T", v)
+	}
+}
@@ -6,11 +6,19 @@ import (
 	"log"
 	"sync"
 
-	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// 支持的数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
 var (
 	db   *gorm.DB
 	once sync.Once
@@ -18,37 +26,55 @@ var (
 
 // Config 数据库配置
 type Config struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	User     string `json:"user"`
-	Password string `json:"password"`
-	Name     string `json:"name"`
-	Charset  string `json:"charset"`
+	Driver   string `json:"driver" yaml:"driver"` // mysql / sqlite / postgres
+	Path     string `json:"path" yaml:"path"`     // SQLite 数据库文件路径
+	SSLMode  string `json:"sslmode" yaml:"sslmode"`
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	User     string `json:"user" yaml:"user"`
+	Password string `json:"password" yaml:"password"`
+	Name     string `json:"name" yaml:"name"`
+	Charset  string `json:"charset" yaml:"charset"`
 }
 
 // GetDefaultConfig 获取默认配置
 func GetDefaultConfig() Config {
 	return Config{
+		Driver:   DriverMySQL,
+		Path:     "ptms.db",
+		SSLMode:  "disable",
 		Host:     "localhost",
-		Port:     3306,
+		Port:     0, // 0 表示按驱动取默认端口，见 DefaultPort
 		User:     "root",
-		Password: "123456", // 修改为你的密码
+		Password: "",
 		Name:     "ptms",
 		Charset:  "utf8mb4",
 	}
 }
 
+// DefaultPort 返回驱动的默认端口，SQLite 不使用端口时返回 0
+func DefaultPort(driver string) int {
+	switch driver {
+	case DriverMySQL, "":
+		return 3306
+	case DriverPostgres:
+		return 5432
+	default:
+		return 0
+	}
+}
+
 // Init 初始化数据库连接
-func Init() (*gorm.DB, error) {
+func Init(cfg Config) (*gorm.DB, error) {
 	var err error
 	once.Do(func() {
-		cfg := GetDefaultConfig()
-
-		// 构建DSN
-		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
-			cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name, cfg.Charset)
+		var dialector gorm.Dialector
+		dialector, err = newDialector(cfg)
+		if err != nil {
+			return
+		}
 
-		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+		db, err = gorm.Open(dialector, &gorm.Config{
 			SkipDefaultTransaction:                   true,
 			DisableForeignKeyConstraintWhenMigrating: true,
 		})
@@ -58,80 +84,79 @@ func Init() (*gorm.DB, error) {
 			return
 		}
 
-		log.Println("✅ 数据库连接成功")
-
-		// 自动迁移表结构
-		err = AutoMigrate()
-		if err != nil {
-			log.Printf("警告: 自动迁移失败: %v", err)
-			// 不返回错误，继续运行（表可能已存在）
-		}
+		log.Printf("✅ 数据库连接成功 (%s)", db.Dialector.Name())
 	})
 
 	return db, err
 }
 
-// AutoMigrate 自动迁移表结构
-func AutoMigrate() error {
-	models := []interface{}{
-		&models.User{},
-		&models.Employee{},
-		&models.Department{},
-		&models.Transfer{},
+// newDialector 根据配置的驱动构建 gorm 方言
+func newDialector(cfg Config) (gorm.Dialector, error) {
+	if cfg.Port == 0 {
+		cfg.Port = DefaultPort(cfg.Driver)
+	}
+	switch cfg.Driver {
+	case DriverMySQL, "":
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
+			cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name, cfg.Charset)
+		return mysql.Open(dsn), nil
+	case DriverSQLite:
+		// 开启 WAL 与忙等待，减少单机并发写入时的 database is locked；
+		// 事务开始时即获取写锁，并发的写事务排队执行，读到的是前一个事务提交后的数据 (乐观锁据此判断冲突)
+		dsn := cfg.Path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+		return sqlite.Open(dsn), nil
+	case DriverPostgres:
+		dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s TimeZone=Local",
+			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode)
+		return postgres.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", cfg.Driver)
+	}
+}
+
+// DropForeignKeys 删除指定表上的外键约束 (按方言处理)。
+// 迁移时已禁用外键创建，这里只用于清理旧版本遗留在本系统表上的约束。
+func DropForeignKeys(tx *gorm.DB, tables ...string) error {
+	var query, stmt string
+	switch tx.Dialector.Name() {
+	case DriverMySQL:
+		query = `
+			SELECT TABLE_NAME AS table_name, CONSTRAINT_NAME AS constraint_name
+			FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS
+			WHERE CONSTRAINT_TYPE = 'FOREIGN KEY' AND TABLE_SCHEMA = DATABASE() AND TABLE_NAME IN ?`
+		stmt = "ALTER TABLE `%s` DROP FOREIGN KEY `%s`"
+	case DriverPostgres:
+		query = `
+			SELECT table_name, constraint_name
+			FROM information_schema.table_constraints
+			WHERE constraint_type = 'FOREIGN KEY' AND table_schema = current_schema() AND table_name IN ?`
+		stmt = `ALTER TABLE "%s" DROP CONSTRAINT "%s"`
+	default:
+		// SQLite 不支持单独删除约束，且本系统从未在 SQLite 上创建外键
+		return nil
+	}
+
+	var fks []struct {
+		TableName      string
+		ConstraintName string
+	}
+	if err := tx.Raw(query, tables).Scan(&fks).Error; err != nil {
+		return fmt.Errorf("查询外键失败: %v", err)
 	}
 
-	for _, model := range models {
-		if err := db.AutoMigrate(model); err != nil {
-			return fmt.Errorf("迁移表失败: %v", err)
-		}
-	}
-
-	if err := DropAllForeignKeys(); err != nil {
-		log.Printf("删除外键失败: %v", err)
-	}
-
-	log.Println("✅ 数据库表结构迁移完成")
-	return nil
-}
-
-func DropAllForeignKeys() error {
-	type ForeignKey struct {
-		TableName      string
-		ConstraintName string
-	}
-
-	var dbName string
-	if err := db.Raw("SELECT DATABASE()").Scan(&dbName).Error; err != nil {
-		return fmt.Errorf("获取当前数据库名失败: %v", err)
-	}
-
-	var fks []ForeignKey
-	if err := db.Raw(`
-		SELECT TABLE_NAME, CONSTRAINT_NAME
-		FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS
-		WHERE CONSTRAINT_TYPE = 'FOREIGN KEY' AND TABLE_SCHEMA = ?
-	`, dbName).Scan(&fks).Error; err != nil {
-		return fmt.Errorf("查询外键失败: %v", err)
-	}
-
-	for _, fk := range fks {
-		stmt := fmt.Sprintf("ALTER TABLE `%s` DROP FOREIGN KEY `%s`", fk.TableName, fk.ConstraintName)
-		if err := db.Exec(stmt).Error; err != nil {
-			log.Printf("删除外键失败 table=%s constraint=%s: %v", fk.TableName, fk.ConstraintName, err)
-		}
-	}
-
-	log.Println("✅ 所有外键约束删除完成")
-	return nil
-}
+	for _, fk := range fks {
+		if err := tx.Exec(fmt.Sprintf(stmt, fk.TableName, fk.ConstraintName)).Error; err != nil {
+			return fmt.Errorf("删除外键失败 table=%s constraint=%s: %v", fk.TableName, fk.ConstraintName, err)
+		}
+		log.Printf("已删除外键 %s.%s", fk.TableName, fk.ConstraintName)
+	}
+	return nil
+}
 
 // GetDB 获取数据库实例
 func GetDB() *gorm.DB {
 	if db == nil {
-		_, err := Init()
-		if err != nil {
-			panic(fmt.Sprintf("获取数据库连接失败: %v", err))
-		}
+		panic("数据库未初始化，请先调用 database.Init")
 	}
 	return db
 }
@@ -0,0 +1,259 @@
+// database/migrate.go
+package database
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 一次版本化的表结构变更。
+// Up/Down 只能依赖迁移文件内定义的表结构快照，不能直接引用 models，
+// 否则模型后续的改动会悄悄改变历史迁移的行为。
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"size:200;not null" json:"name"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
+
+// TableName 指定表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationState 迁移状态
+type MigrationState struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// sortedMigrations 按版本号排序后的迁移列表，并检查版本号是否重复
+func sortedMigrations() ([]Migration, error) {
+	list := append([]Migration{}, migrations...)
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	for i := 1; i < len(list); i++ {
+		if list[i].Version == list[i-1].Version {
+			return nil, fmt.Errorf("迁移版本号重复: %d", list[i].Version)
+		}
+	}
+	return list, nil
+}
+
+// appliedVersions 读取已执行的迁移版本
+func appliedVersions() (map[int]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("创建 schema_migrations 表失败: %v", err)
+	}
+
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %v", err)
+	}
+
+	applied := make(map[int]SchemaMigration, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// MigrationStatus 列出所有迁移及其执行状态
+func MigrationStatus() ([]MigrationState, error) {
+	list, err := sortedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, len(list))
+	for i, m := range list {
+		states[i] = MigrationState{Version: m.Version, Name: m.Name}
+		if r, ok := applied[m.Version]; ok {
+			appliedAt := r.AppliedAt
+			states[i].Applied = true
+			states[i].AppliedAt = &appliedAt
+		}
+	}
+	return states, nil
+}
+
+// PendingMigrations 返回尚未执行的迁移
+func PendingMigrations() ([]Migration, error) {
+	list, err := sortedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range list {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// SchemaVersion 当前已执行的最高迁移版本，0 表示空库
+func SchemaVersion() (int, error) {
+	applied, err := appliedVersions()
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// LatestVersion 代码中定义的最高迁移版本
+func LatestVersion() int {
+	version := 0
+	for _, m := range migrations {
+		if m.Version > version {
+			version = m.Version
+		}
+	}
+	return version
+}
+
+// MigrateUp 按版本顺序执行所有未执行的迁移，每个迁移在独立事务中完成。
+// MySQL 的 DDL 会隐式提交事务，迁移中途失败时已执行的表结构变更 (及其之前的数据修改) 不会回滚，
+// 因此迁移中的表结构操作都通过 migrator 执行，修复问题后重新执行 migrate up 会跳过已完成的步骤。
+func MigrateUp() ([]Migration, error) {
+	pending, err := PendingMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range pending {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("执行迁移 %04d_%s 失败: %v", m.Version, m.Name, err)
+		}
+		log.Printf("✅ 已执行迁移 %04d_%s", m.Version, m.Name)
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// schemaMigrator 幂等的表结构操作：列、索引、表已是目标状态时直接跳过，
+// 使中途失败 (MySQL 无法回滚 DDL) 的迁移可以重新执行
+type schemaMigrator struct {
+	gorm.Migrator
+}
+
+// migrator 迁移中使用的表结构操作，替代 tx.Migrator()
+func migrator(tx *gorm.DB) schemaMigrator {
+	return schemaMigrator{tx.Migrator()}
+}
+
+func (m schemaMigrator) CreateTable(models ...interface{}) error {
+	for _, model := range models {
+		if m.HasTable(model) {
+			continue
+		}
+		if err := m.Migrator.CreateTable(model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m schemaMigrator) AddColumn(model interface{}, field string) error {
+	if m.HasColumn(model, field) {
+		return nil
+	}
+	return m.Migrator.AddColumn(model, field)
+}
+
+func (m schemaMigrator) DropColumn(model interface{}, field string) error {
+	if !m.HasColumn(model, field) {
+		return nil
+	}
+	return m.Migrator.DropColumn(model, field)
+}
+
+// RenameColumn 原列已不存在且新列已存在时视为已改名
+func (m schemaMigrator) RenameColumn(model interface{}, oldName, newName string) error {
+	if !m.HasColumn(model, oldName) && m.HasColumn(model, newName) {
+		return nil
+	}
+	return m.Migrator.RenameColumn(model, oldName, newName)
+}
+
+func (m schemaMigrator) CreateIndex(model interface{}, name string) error {
+	if m.HasIndex(model, name) {
+		return nil
+	}
+	return m.Migrator.CreateIndex(model, name)
+}
+
+func (m schemaMigrator) DropIndex(model interface{}, name string) error {
+	if !m.HasIndex(model, name) {
+		return nil
+	}
+	return m.Migrator.DropIndex(model, name)
+}
+
+// MigrateDown 回滚最近一次执行的迁移，没有可回滚的迁移时返回 nil
+func MigrateDown() (*Migration, error) {
+	list, err := sortedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(list) - 1; i >= 0; i-- {
+		m := list[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == nil {
+			return nil, fmt.Errorf("迁移 %04d_%s 不支持回滚", m.Version, m.Name)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return nil, fmt.Errorf("回滚迁移 %04d_%s 失败: %v", m.Version, m.Name, err)
+		}
+		log.Printf("✅ 已回滚迁移 %04d_%s", m.Version, m.Name)
+		return &m, nil
+	}
+	return nil, nil
+}
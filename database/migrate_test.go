@@ -0,0 +1,82 @@
+// database/migrate_test.go
+package database
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 测试使用临时目录中的空 SQLite 数据库
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "hrms-database-test-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	log.SetOutput(io.Discard)
+	cfg := GetDefaultConfig()
+	cfg.Driver = DriverSQLite
+	cfg.Path = filepath.Join(dir, "test.db")
+	if _, err := Init(cfg); err != nil {
+		log.Fatal(err)
+	}
+	return m.Run()
+}
+
+// 每个迁移的 Up/Down 重复执行都不出错，模拟 MySQL 上 DDL 已提交、但迁移记录未写入后重新执行
+func TestMigrationsRerunnable(t *testing.T) {
+	list, err := sortedMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := appliedVersions(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range list {
+		for i := 0; i < 2; i++ {
+			if err := db.Transaction(m.Up); err != nil {
+				t.Fatalf("第 %d 次执行迁移 %04d_%s 失败: %v", i+1, m.Version, m.Name, err)
+			}
+		}
+		if err := db.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if version, err := SchemaVersion(); err != nil || version != LatestVersion() {
+		t.Fatalf("SchemaVersion = %d, %v，期望 %d", version, err, LatestVersion())
+	}
+
+	for i := len(list) - 1; i >= 0; i-- {
+		m := list[i]
+		for j := 0; j < 2; j++ {
+			if err := db.Transaction(m.Down); err != nil {
+				t.Fatalf("第 %d 次回滚迁移 %04d_%s 失败: %v", j+1, m.Version, m.Name, err)
+			}
+		}
+		if err := db.Delete(&SchemaMigration{}, m.Version).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 全部回滚后可以重新执行
+	done, err := MigrateUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != len(list) {
+		t.Errorf("执行了 %d 个迁移，期望 %d", len(done), len(list))
+	}
+	if pending, err := PendingMigrations(); err != nil || len(pending) != 0 {
+		t.Errorf("仍有 %d 个未执行的迁移 (%v)", len(pending), err)
+	}
+}
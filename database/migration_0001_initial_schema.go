@@ -0,0 +1,89 @@
+// database/migration_0001_initial_schema.go
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 0001 初始表结构。
+// 旧版本在启动时 AutoMigrate 建表，这里同样使用 AutoMigrate，
+// 对已有库是幂等的，只会补齐缺失的列和索引。
+
+type userV1 struct {
+	ID        uint   `gorm:"primaryKey"`
+	Username  string `gorm:"size:50;unique;not null"`
+	Password  string `gorm:"size:255;not null"`
+	Role      int    `gorm:"not null;default:1"`
+	RealName  string `gorm:"size:50"`
+	Email     string `gorm:"size:100"`
+	Phone     string `gorm:"size:20"`
+	LastLogin time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (userV1) TableName() string { return "users" }
+
+type employeeV1 struct {
+	ID          uint   `gorm:"primaryKey"`
+	EmployeeID  string `gorm:"column:employee_id;size:20;uniqueIndex;not null"`
+	Name        string `gorm:"size:50;not null"`
+	Status      int    `gorm:"not null;default:1"`
+	ArrivalDate string `gorm:"type:date;not null"`
+	JobTitle    string `gorm:"size:100"`
+	Position    string `gorm:"size:100"`
+	Department  string `gorm:"size:100"`
+	Phone       string `gorm:"size:20"`
+	Email       string `gorm:"size:100"`
+	Address     string `gorm:"type:text"`
+	Remark      string `gorm:"type:text"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (employeeV1) TableName() string { return "employees" }
+
+type departmentV1 struct {
+	ID        uint   `gorm:"primaryKey"`
+	DeptNo    string `gorm:"size:20;uniqueIndex;not null"`
+	Name      string `gorm:"size:100;not null"`
+	ManagerID uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (departmentV1) TableName() string { return "departments" }
+
+type transferV1 struct {
+	ID           uint   `gorm:"primaryKey"`
+	EmployeeID   uint   `gorm:"not null;index"`
+	TransferDate string `gorm:"type:date;not null"`
+	Type         int    `gorm:"not null"`
+	FromDeptID   *uint
+	ToDeptID     *uint
+	Reason       string `gorm:"type:text"`
+	Status       int    `gorm:"not null;default:1"`
+	SubmitterID  uint   `gorm:"index"`
+	ApproverID   uint
+	ApprovedAt   *time.Time
+	CreatedAt    time.Time
+}
+
+func (transferV1) TableName() string { return "transfers" }
+
+var migration0001InitialSchema = Migration{
+	Version: 1,
+	Name:    "initial_schema",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(&userV1{}, &employeeV1{}, &departmentV1{}, &transferV1{}); err != nil {
+			return err
+		}
+		// 早期版本可能遗留了外键约束，仅清理本系统自己的表
+		return DropForeignKeys(tx, "users", "employees", "departments", "transfers")
+	},
+	Down: func(tx *gorm.DB) error {
+		return migrator(tx).DropTable(&transferV1{}, &departmentV1{}, &employeeV1{}, &userV1{})
+	},
+}
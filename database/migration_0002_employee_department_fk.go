@@ -0,0 +1,106 @@
+// database/migration_0002_employee_department_fk.go
+package database
+
+import (
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 0002 员工所在部门由文本改为关联 departments.id。
+// 原 department 文本按部门名称（其次部门编号）匹配，匹配不上的会打印出来，
+// 原文本列改名为 department_legacy 保留，便于人工核对后补录。
+
+type employeeV2 struct {
+	ID               uint   `gorm:"primaryKey"`
+	EmployeeID       string `gorm:"column:employee_id"`
+	Name             string
+	DepartmentID     *uint  `gorm:"index"`
+	DepartmentLegacy string `gorm:"column:department_legacy;size:100"`
+}
+
+func (employeeV2) TableName() string { return "employees" }
+
+var migration0002EmployeeDepartmentFK = Migration{
+	Version: 2,
+	Name:    "employee_department_fk",
+	Up: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		if err := m.AddColumn(&employeeV2{}, "DepartmentID"); err != nil {
+			return err
+		}
+		if err := m.CreateIndex(&employeeV2{}, "DepartmentID"); err != nil {
+			return err
+		}
+		// 部门文本列已改名说明上次执行时数据已迁移完成，只是未记录迁移版本
+		if !m.HasColumn(&employeeV1{}, "Department") {
+			return nil
+		}
+
+		var depts []departmentV1
+		if err := tx.Find(&depts).Error; err != nil {
+			return err
+		}
+		byName := make(map[string]uint, len(depts))
+		byNo := make(map[string]uint, len(depts))
+		for _, d := range depts {
+			byName[strings.TrimSpace(d.Name)] = d.ID
+			byNo[strings.TrimSpace(d.DeptNo)] = d.ID
+		}
+
+		var emps []employeeV1
+		if err := tx.Where("department <> ''").Find(&emps).Error; err != nil {
+			return err
+		}
+		unmatched := 0
+		for _, e := range emps {
+			name := strings.TrimSpace(e.Department)
+			deptID, ok := byName[name]
+			if !ok {
+				deptID, ok = byNo[name]
+			}
+			if !ok {
+				unmatched++
+				log.Printf("⚠️ 员工 %s (%s) 的部门「%s」未匹配到部门记录，已保留在 department_legacy 列", e.EmployeeID, e.Name, e.Department)
+				continue
+			}
+			if err := tx.Model(&employeeV2{}).Where("id = ?", e.ID).Update("department_id", deptID).Error; err != nil {
+				return err
+			}
+		}
+		log.Printf("员工部门关联: 共 %d 条，匹配 %d 条，未匹配 %d 条", len(emps), len(emps)-unmatched, unmatched)
+
+		return m.RenameColumn(&employeeV2{}, "department", "department_legacy")
+	},
+	Down: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		if err := m.RenameColumn(&employeeV1{}, "department_legacy", "department"); err != nil {
+			return err
+		}
+
+		// department_id 已删除说明上次回滚时数据已还原
+		if !m.HasColumn(&employeeV2{}, "DepartmentID") {
+			return nil
+		}
+
+		var emps []employeeV2
+		if err := tx.Select("id", "department_id").Where("department_id IS NOT NULL").Find(&emps).Error; err != nil {
+			return err
+		}
+		for _, e := range emps {
+			var dept departmentV1
+			if err := tx.First(&dept, *e.DepartmentID).Error; err != nil {
+				continue
+			}
+			if err := tx.Model(&employeeV1{}).Where("id = ?", e.ID).Update("department", dept.Name).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := m.DropIndex(&employeeV2{}, "DepartmentID"); err != nil {
+			return err
+		}
+		return m.DropColumn(&employeeV2{}, "DepartmentID")
+	},
+}
@@ -0,0 +1,39 @@
+// database/migration_0003_transfer_position.go
+package database
+
+import "gorm.io/gorm"
+
+// 0003 调动记录增加职位调动所需的原/新职位、职务
+
+type transferV3 struct {
+	ID           uint   `gorm:"primaryKey"`
+	FromPosition string `gorm:"size:100"`
+	ToPosition   string `gorm:"size:100"`
+	FromJobTitle string `gorm:"size:100"`
+	ToJobTitle   string `gorm:"size:100"`
+}
+
+func (transferV3) TableName() string { return "transfers" }
+
+var transferV3Columns = []string{"FromPosition", "ToPosition", "FromJobTitle", "ToJobTitle"}
+
+var migration0003TransferPosition = Migration{
+	Version: 3,
+	Name:    "transfer_position",
+	Up: func(tx *gorm.DB) error {
+		for _, field := range transferV3Columns {
+			if err := migrator(tx).AddColumn(&transferV3{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		for _, field := range transferV3Columns {
+			if err := migrator(tx).DropColumn(&transferV3{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
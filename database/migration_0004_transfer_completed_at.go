@@ -0,0 +1,48 @@
+// database/migration_0004_transfer_completed_at.go
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 0004 调动按生效日期执行，增加 completed_at。
+// 此前批准即立即修改员工档案，历史上"已批准"的记录实际都已生效，
+// 这里统一标记为"已完成"，避免定时任务再次执行。
+
+type transferV4 struct {
+	ID          uint `gorm:"primaryKey"`
+	Status      int
+	ApprovedAt  *time.Time
+	CompletedAt *time.Time
+}
+
+func (transferV4) TableName() string { return "transfers" }
+
+const (
+	transferV4StatusApproved  = 2
+	transferV4StatusCompleted = 4
+)
+
+var migration0004TransferCompletedAt = Migration{
+	Version: 4,
+	Name:    "transfer_completed_at",
+	Up: func(tx *gorm.DB) error {
+		if err := migrator(tx).AddColumn(&transferV4{}, "CompletedAt"); err != nil {
+			return err
+		}
+		return tx.Model(&transferV4{}).Where("status = ?", transferV4StatusApproved).
+			Updates(map[string]interface{}{
+				"status":       transferV4StatusCompleted,
+				"completed_at": gorm.Expr("approved_at"),
+			}).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Model(&transferV4{}).Where("status = ?", transferV4StatusCompleted).
+			Update("status", transferV4StatusApproved).Error; err != nil {
+			return err
+		}
+		return migrator(tx).DropColumn(&transferV4{}, "CompletedAt")
+	},
+}
@@ -0,0 +1,81 @@
+// database/migration_0005_transfer_approval_steps.go
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 0005 多级审批：新增审批步骤表，用户可关联员工档案（部门主管审批）。
+// 已有的待审批调动补一个"审批人"步骤，与此前单级审批的行为一致。
+
+type userV5 struct {
+	ID         uint  `gorm:"primaryKey"`
+	EmployeeID *uint `gorm:"uniqueIndex"`
+}
+
+func (userV5) TableName() string { return "users" }
+
+type transferApprovalStepV5 struct {
+	ID           uint   `gorm:"primaryKey"`
+	TransferID   uint   `gorm:"not null;index"`
+	StepNo       int    `gorm:"not null"`
+	ApproverType string `gorm:"size:50;not null"`
+	DeptID       *uint
+	Status       int `gorm:"not null;default:1"`
+	ActorID      uint
+	Comment      string `gorm:"type:text"`
+	ActedAt      *time.Time
+	CreatedAt    time.Time
+}
+
+func (transferApprovalStepV5) TableName() string { return "transfer_approval_steps" }
+
+const transferV5StatusPending = 1
+
+var migration0005TransferApprovalSteps = Migration{
+	Version: 5,
+	Name:    "transfer_approval_steps",
+	Up: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		if err := m.AddColumn(&userV5{}, "EmployeeID"); err != nil {
+			return err
+		}
+		if err := m.CreateIndex(&userV5{}, "EmployeeID"); err != nil {
+			return err
+		}
+		if err := m.CreateTable(&transferApprovalStepV5{}); err != nil {
+			return err
+		}
+
+		var pendingIDs []uint
+		if err := tx.Table("transfers").Where("status = ?", transferV5StatusPending).Pluck("id", &pendingIDs).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, id := range pendingIDs {
+			step := transferApprovalStepV5{
+				TransferID:   id,
+				StepNo:       1,
+				ApproverType: "approver",
+				Status:       1,
+				CreatedAt:    now,
+			}
+			if err := tx.Create(&step).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		if err := m.DropTable(&transferApprovalStepV5{}); err != nil {
+			return err
+		}
+		if err := m.DropIndex(&userV5{}, "EmployeeID"); err != nil {
+			return err
+		}
+		return m.DropColumn(&userV5{}, "EmployeeID")
+	},
+}
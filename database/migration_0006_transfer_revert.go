@@ -0,0 +1,51 @@
+// database/migration_0006_transfer_revert.go
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 0006 调动支持撤回、取消与撤销：记录生效前后的员工状态、反向调动来源及操作信息。
+// 历史已完成的调动没有记录生效前状态，离退休类调动 (以及未填写调出部门的部门调动) 无法撤销，需人工修改员工档案。
+
+type transferV6 struct {
+	ID            uint  `gorm:"primaryKey"`
+	FromStatus    int   `gorm:"not null;default:0"`
+	ToStatus      int   `gorm:"not null;default:0"`
+	RevertOfID    *uint `gorm:"index"`
+	CancelledByID uint
+	CancelledAt   *time.Time
+	CancelReason  string `gorm:"type:text"`
+}
+
+func (transferV6) TableName() string { return "transfers" }
+
+var transferV6Columns = []string{"FromStatus", "ToStatus", "RevertOfID", "CancelledByID", "CancelledAt", "CancelReason"}
+
+var migration0006TransferRevert = Migration{
+	Version: 6,
+	Name:    "transfer_revert",
+	Up: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		for _, field := range transferV6Columns {
+			if err := m.AddColumn(&transferV6{}, field); err != nil {
+				return err
+			}
+		}
+		return m.CreateIndex(&transferV6{}, "RevertOfID")
+	},
+	Down: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		if err := m.DropIndex(&transferV6{}, "RevertOfID"); err != nil {
+			return err
+		}
+		for _, field := range transferV6Columns {
+			if err := m.DropColumn(&transferV6{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,36 @@
+// database/migration_0007_audit_logs.go
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 0007 审计日志表
+
+type auditLogV7 struct {
+	ID        uint      `gorm:"primaryKey"`
+	Entity    string    `gorm:"size:50;not null;index:idx_audit_entity"`
+	EntityID  uint      `gorm:"not null;index:idx_audit_entity"`
+	Action    string    `gorm:"size:20;not null"`
+	ActorID   uint      `gorm:"index"`
+	ActorName string    `gorm:"size:50"`
+	Source    string    `gorm:"size:20"`
+	RequestID string    `gorm:"size:64;index"`
+	Changes   string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (auditLogV7) TableName() string { return "audit_logs" }
+
+var migration0007AuditLogs = Migration{
+	Version: 7,
+	Name:    "audit_logs",
+	Up: func(tx *gorm.DB) error {
+		return migrator(tx).CreateTable(&auditLogV7{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return migrator(tx).DropTable(&auditLogV7{})
+	},
+}
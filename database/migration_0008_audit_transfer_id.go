@@ -0,0 +1,33 @@
+// database/migration_0008_audit_transfer_id.go
+package database
+
+import "gorm.io/gorm"
+
+// 0008 审计日志关联调动ID，区分调动生效引起的员工变更与直接修改，
+// 按时间点还原员工信息时前者以调动生效日期为准
+
+type auditLogV8 struct {
+	ID         uint  `gorm:"primaryKey"`
+	TransferID *uint `gorm:"index"`
+}
+
+func (auditLogV8) TableName() string { return "audit_logs" }
+
+var migration0008AuditTransferID = Migration{
+	Version: 8,
+	Name:    "audit_transfer_id",
+	Up: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		if err := m.AddColumn(&auditLogV8{}, "TransferID"); err != nil {
+			return err
+		}
+		return m.CreateIndex(&auditLogV8{}, "TransferID")
+	},
+	Down: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		if err := m.DropIndex(&auditLogV8{}, "TransferID"); err != nil {
+			return err
+		}
+		return m.DropColumn(&auditLogV8{}, "TransferID")
+	},
+}
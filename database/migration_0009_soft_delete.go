@@ -0,0 +1,53 @@
+// database/migration_0009_soft_delete.go
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 0009 员工与部门改为软删除：删除时只记录删除时间，超过保留期限后由管理员清除
+
+type employeeV9 struct {
+	ID        uint       `gorm:"primaryKey"`
+	DeletedAt *time.Time `gorm:"index"`
+}
+
+func (employeeV9) TableName() string { return "employees" }
+
+type departmentV9 struct {
+	ID        uint       `gorm:"primaryKey"`
+	DeletedAt *time.Time `gorm:"index"`
+}
+
+func (departmentV9) TableName() string { return "departments" }
+
+var migration0009SoftDelete = Migration{
+	Version: 9,
+	Name:    "soft_delete",
+	Up: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		for _, model := range []interface{}{&employeeV9{}, &departmentV9{}} {
+			if err := m.AddColumn(model, "DeletedAt"); err != nil {
+				return err
+			}
+			if err := m.CreateIndex(model, "DeletedAt"); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		for _, model := range []interface{}{&employeeV9{}, &departmentV9{}} {
+			if err := m.DropIndex(model, "DeletedAt"); err != nil {
+				return err
+			}
+			if err := m.DropColumn(model, "DeletedAt"); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,40 @@
+// database/migration_0010_transfer_status_types.go
+package database
+
+import "gorm.io/gorm"
+
+// 0010 离职、返聘、试用转正、转兼职作为调动类型：记录离职的最后工作日和返聘合同期限
+
+type transferV10 struct {
+	ID                uint    `gorm:"primaryKey"`
+	LastWorkingDay    *string `gorm:"type:date"`
+	ContractStartDate *string `gorm:"type:date"`
+	ContractEndDate   *string `gorm:"type:date"`
+}
+
+func (transferV10) TableName() string { return "transfers" }
+
+var transferV10Columns = []string{"LastWorkingDay", "ContractStartDate", "ContractEndDate"}
+
+var migration0010TransferStatusTypes = Migration{
+	Version: 10,
+	Name:    "transfer_status_types",
+	Up: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		for _, field := range transferV10Columns {
+			if err := m.AddColumn(&transferV10{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		for _, field := range transferV10Columns {
+			if err := m.DropColumn(&transferV10{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,37 @@
+// database/migration_0011_backup_runs.go
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 0011 自动备份执行记录
+
+type backupRunV11 struct {
+	ID         uint   `gorm:"primaryKey"`
+	Trigger    string `gorm:"size:20;not null"`
+	Status     string `gorm:"size:20;not null;index"`
+	FileName   string `gorm:"size:255"`
+	Size       int64
+	SHA256     string `gorm:"column:sha256;size:64"`
+	DurationMs int64
+	Error      string `gorm:"type:text"`
+	PrunedAt   *time.Time
+	StartedAt  time.Time `gorm:"index"`
+	FinishedAt time.Time
+}
+
+func (backupRunV11) TableName() string { return "backup_runs" }
+
+var migration0011BackupRuns = Migration{
+	Version: 11,
+	Name:    "backup_runs",
+	Up: func(tx *gorm.DB) error {
+		return migrator(tx).CreateTable(&backupRunV11{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return migrator(tx).DropTable(&backupRunV11{})
+	},
+}
@@ -0,0 +1,32 @@
+// database/migration_0012_department_parent.go
+package database
+
+import "gorm.io/gorm"
+
+// 0012 部门增加上级部门，支持多级组织架构 (事业部 / 部门 / 小组)
+
+type departmentV12 struct {
+	ID       uint  `gorm:"primaryKey"`
+	ParentID *uint `gorm:"index"`
+}
+
+func (departmentV12) TableName() string { return "departments" }
+
+var migration0012DepartmentParent = Migration{
+	Version: 12,
+	Name:    "department_parent",
+	Up: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		if err := m.AddColumn(&departmentV12{}, "ParentID"); err != nil {
+			return err
+		}
+		return m.CreateIndex(&departmentV12{}, "ParentID")
+	},
+	Down: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		if err := m.DropIndex(&departmentV12{}, "ParentID"); err != nil {
+			return err
+		}
+		return m.DropColumn(&departmentV12{}, "ParentID")
+	},
+}
@@ -0,0 +1,51 @@
+// database/migration_0013_version_columns.go
+package database
+
+import "gorm.io/gorm"
+
+// 0013 员工、部门和调动增加版本号，用于乐观锁：每次修改版本号加一，
+// 更新时带上读取时的版本号作为条件，防止并发修改互相覆盖
+
+type employeeV13 struct {
+	ID      uint `gorm:"primaryKey"`
+	Version uint `gorm:"not null;default:1"`
+}
+
+func (employeeV13) TableName() string { return "employees" }
+
+type departmentV13 struct {
+	ID      uint `gorm:"primaryKey"`
+	Version uint `gorm:"not null;default:1"`
+}
+
+func (departmentV13) TableName() string { return "departments" }
+
+type transferV13 struct {
+	ID      uint `gorm:"primaryKey"`
+	Version uint `gorm:"not null;default:1"`
+}
+
+func (transferV13) TableName() string { return "transfers" }
+
+var migration0013VersionColumns = Migration{
+	Version: 13,
+	Name:    "version_columns",
+	Up: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		for _, model := range []interface{}{&employeeV13{}, &departmentV13{}, &transferV13{}} {
+			if err := m.AddColumn(model, "Version"); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		m := migrator(tx)
+		for _, model := range []interface{}{&employeeV13{}, &departmentV13{}, &transferV13{}} {
+			if err := m.DropColumn(model, "Version"); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,19 @@
+// database/migrations.go
+package database
+
+// migrations 全部版本化迁移，新迁移追加在末尾，已发布的迁移不得再修改
+var migrations = []Migration{
+	migration0001InitialSchema,
+	migration0002EmployeeDepartmentFK,
+	migration0003TransferPosition,
+	migration0004TransferCompletedAt,
+	migration0005TransferApprovalSteps,
+	migration0006TransferRevert,
+	migration0007AuditLogs,
+	migration0008AuditTransferID,
+	migration0009SoftDelete,
+	migration0010TransferStatusTypes,
+	migration0011BackupRuns,
+	migration0012DepartmentParent,
+	migration0013VersionColumns,
+}
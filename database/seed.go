@@ -0,0 +1,52 @@
+// database/seed.go
+package database
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// SeedAdmin 系统中没有任何管理员时创建初始管理员账号
+func SeedAdmin(username, password string) error {
+	if username == "" || password == "" {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&count).Error; err != nil {
+		return fmt.Errorf("查询管理员失败: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("密码加密失败: %v", err)
+	}
+
+	admin := models.User{
+		Username:  username,
+		Password:  string(hashedPassword),
+		Role:      models.RoleAdmin,
+		LastLogin: time.Now(),
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&admin).Error; err != nil {
+			return err
+		}
+		return audit.Created(tx, models.AuditEntityUser, admin.ID, admin)
+	})
+	if err != nil {
+		return fmt.Errorf("创建初始管理员失败: %v", err)
+	}
+
+	log.Printf("✅ 已创建初始管理员账号: %s", username)
+	return nil
+}
@@ -2,82 +2,193 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/api"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/config"
 	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
-	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/middleware"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/scheduler"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/service"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	configPath := flag.String("config", "", "配置文件路径 (默认读取 HRMS_CONFIG 或 ./config.yaml)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// 0. 加载并校验配置
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("❌ 加载配置失败: %v", err)
+	}
+	utils.Setup(cfg.JWT.Secret, cfg.JWT.Expire, cfg.JWT.Issuer)
+	service.SetupApproval(cfg.Approval.Chains)
+	gin.SetMode(cfg.Server.Mode)
+
 	// 1. 初始化数据库
-	db, err := database.Init()
+	if _, err := database.Init(cfg.Database); err != nil {
+		log.Fatalf("❌ 数据库连接失败: %v", err)
+	}
+
+	// 子命令 (migrate up|down|status) 执行完即退出
+	if args := flag.Args(); len(args) > 0 {
+		if err := runCommand(cfg, args); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		return
+	}
+
+	// 2. 表结构版本必须与代码一致，迁移需通过 migrate up 显式执行
+	pending, err := database.PendingMigrations()
 	if err != nil {
-		log.Printf("⚠️ 数据库连接失败: %v", err)
-	} else {
-		// 自动迁移所有模型表 (确保包含 Transfer 和 Department)
-		db.AutoMigrate(&models.User{}, &models.Employee{}, &models.Department{}, &models.Transfer{})
-		log.Println("✅ 数据库表结构同步完成")
+		log.Fatalf("❌ 检查数据库版本失败: %v", err)
+	}
+	if len(pending) > 0 {
+		log.Fatalf("❌ 数据库结构落后 %d 个版本，请先执行: %s migrate up", len(pending), os.Args[0])
+	}
+	if version, err := database.SchemaVersion(); err == nil && version > database.LatestVersion() {
+		log.Fatalf("❌ 数据库结构版本 (%d) 高于当前程序 (%d)，请升级程序或执行 migrate down", version, database.LatestVersion())
+	}
+
+	if err := database.SeedAdmin(cfg.Admin.Username, cfg.Admin.Password); err != nil {
+		log.Printf("⚠️ %v", err)
 	}
 
 	r := gin.Default()
 
 	// CORS 中间件
-	r.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")                                   //允许所有域名（*）访问你的 API
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")    //允许的 HTTP 方法
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Token") //允许客户端携带的请求头
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		c.Next()
-	})
+	r.Use(middleware.CORS(cfg.Server.CORSOrigins))
+	// 请求ID (X-Request-ID)，用于关联审计日志
+	r.Use(middleware.RequestID())
 
 	// 实例化控制器
 	authCtrl := api.AuthController{}
 	empCtrl := api.EmployeeController{}
 	deptCtrl := api.DepartmentController{} // 新增
 	transCtrl := api.TransferController{}  // 新增
-	backupCtrl := api.BackupController{}   // 新增
+	backupCtrl := api.BackupController{Dir: cfg.Backup.Dir, Keep: cfg.Backup.Keep, Encryption: cfg.Backup.Encryption,
+		MaxRestoreSize: int64(cfg.Backup.MaxRestoreMB) << 20}
+	userCtrl := api.UserController{}
+	auditCtrl := api.AuditController{}
+	maintenanceCtrl := api.MaintenanceController{PurgeAfter: cfg.Retention.PurgeAfter}
 
+	// 公开接口：仅登录无需令牌
+	r.POST("/api/login", authCtrl.Login)
+
+	// 其余 /api 接口均需携带有效的 JWT，并按 routePolicy 校验角色权限
 	apiGroup := r.Group("/api")
+	apiGroup.Use(middleware.JWTAuth(), middleware.Authorize(routePolicy))
 	{
 		// --- 认证模块 ---
-		apiGroup.POST("/login", authCtrl.Login)
-		apiGroup.POST("/register", authCtrl.Register) // 开发测试用
+		apiGroup.POST("/register", authCtrl.Register) // 管理员创建账号
+		apiGroup.GET("/profile", authCtrl.GetProfile)
+
+		// --- 用户与角色管理 ---
+		apiGroup.GET("/users", userCtrl.GetUsers)
+		apiGroup.PUT("/users/:id/role", userCtrl.UpdateUserRole)
+		apiGroup.PUT("/users/:id/employee", userCtrl.UpdateUserEmployee) // 关联员工档案 (部门主管审批)
 
 		// --- 员工管理模块 ---
 		apiGroup.GET("/employees", empCtrl.GetEmployees)
 		apiGroup.GET("/employees/:id", empCtrl.GetEmployee)
+		apiGroup.GET("/employees/:id/as-of", empCtrl.GetEmployeeAsOf)         // 按日期还原员工信息
+		apiGroup.GET("/employees/:id/next-statuses", empCtrl.GetNextStatuses) // 可变更到的员工状态
 		apiGroup.POST("/employees", empCtrl.CreateEmployee)
+		apiGroup.POST("/employees/import", empCtrl.ImportEmployees) // 从 CSV / XLSX 批量导入
 		apiGroup.PUT("/employees/:id", empCtrl.UpdateEmployee)
 		apiGroup.DELETE("/employees/:id", empCtrl.DeleteEmployee)
+		apiGroup.PUT("/employees/:id/restore", empCtrl.RestoreEmployee) // 恢复已删除的员工
 
 		// --- 部门管理模块 (新增) ---
 		apiGroup.GET("/departments", deptCtrl.GetDepartments)
+		apiGroup.GET("/departments/headcount", deptCtrl.GetHeadcount) // 按日期统计各部门人数
+		apiGroup.GET("/departments/tree", deptCtrl.GetDepartmentTree) // 部门树及各级人数
 		apiGroup.POST("/departments", deptCtrl.CreateDepartment)
 		apiGroup.PUT("/departments/:id", deptCtrl.UpdateDepartment)
 		apiGroup.DELETE("/departments/:id", deptCtrl.DeleteDepartment)
+		apiGroup.PUT("/departments/:id/restore", deptCtrl.RestoreDepartment) // 恢复已删除的部门
+		apiGroup.PUT("/departments/:id/move", deptCtrl.MoveDepartment)       // 调整上级部门
+		apiGroup.POST("/departments/:id/merge", deptCtrl.MergeDepartment)    // 合并到另一个部门
+		apiGroup.POST("/departments/:id/split", deptCtrl.SplitDepartment)    // 按员工名单拆分
 
 		// --- 调动管理子系统 (新增) ---
 		// 1. 提交调动/退休申请
 		apiGroup.POST("/transfers", transCtrl.CreateTransfer)
+		apiGroup.POST("/transfers/batch", transCtrl.BatchTransfers) // 批量提交 (整批成功或全部回滚)
 		// 2. 获取调动记录列表 (可筛选待审批)
 		apiGroup.GET("/transfers", transCtrl.GetTransfers)
-		// 3. 审批调动 (通过后自动更新员工表)
+		// 3. 审批调动 (按审批链逐级审批，全部通过且到生效日期后更新员工表)
 		apiGroup.PUT("/transfers/:id/approve", transCtrl.ApproveTransfer)
+		apiGroup.PUT("/transfers/approve-batch", transCtrl.ApproveBatch)  // 批量审批 (整批成功或全部回滚)
+		apiGroup.GET("/transfers/my-approvals", transCtrl.GetMyApprovals) // 当前等待我审批的申请
+		// 4. 撤回 (提交人) / 取消 (未生效) / 撤销 (已生效，生成反向调动)
+		apiGroup.PUT("/transfers/:id/withdraw", transCtrl.WithdrawTransfer)
+		apiGroup.PUT("/transfers/:id/cancel", transCtrl.CancelTransfer)
+		apiGroup.POST("/transfers/:id/revert", transCtrl.RevertTransfer)
+		// 5. 立即执行到期调动 (管理员手动触发定时任务)
+		apiGroup.POST("/transfers/run-scheduled", transCtrl.RunScheduledTransfers)
 
 		// --- 系统维护模块 (新增) ---
 		// 导出员工数据备份
 		apiGroup.GET("/backup/export", backupCtrl.ExportEmployees)
+		// 完整备份 (全部表) 与恢复
+		apiGroup.GET("/backup/full", backupCtrl.CreateBackup)
+		apiGroup.POST("/backup/restore", backupCtrl.RestoreBackup)
+		// 备份目录中的备份：执行记录与立即备份
+		apiGroup.GET("/backup/history", backupCtrl.GetBackupHistory)
+		apiGroup.POST("/backup/run", backupCtrl.RunBackup)
+		// 审计日志 (员工/部门/调动/用户的新增、修改、删除记录)
+		apiGroup.GET("/audit", auditCtrl.GetAuditLogs)
+		// 彻底清除超过保留期限的已删除员工和部门
+		apiGroup.POST("/maintenance/purge", maintenanceCtrl.PurgeDeleted)
+	}
+
+	checkRoutePolicy(r.Routes())
+
+	// 收到退出信号时停止后台任务并优雅关闭服务
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 后台任务：按生效日期执行已批准的调动
+	scheduler.StartTransferJob(ctx, cfg.Scheduler.TransferInterval)
+	// 后台任务：按 backup.schedule 定期备份
+	backupDone, err := scheduler.StartBackupJob(ctx, cfg.Backup.Schedule, cfg.Backup.Dir, cfg.Backup.Keep, cfg.Backup.Encryption)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
 	}
 
 	// 启动服务
-	port := ":8080"
-	log.Printf("🚀 服务器启动在 http://localhost%s", port)
-	if err := r.Run(port); err != nil {
-		log.Fatal(err)
+	addr := cfg.Server.Addr
+	srv := &http.Server{Addr: addr, Handler: r}
+	go func() {
+		log.Printf("🚀 服务器启动在 %s", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("正在关闭服务...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️ 关闭服务失败: %v", err)
 	}
+	// 等待正在执行的自动备份完成，避免留下不完整的备份文件
+	<-backupDone
 }
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+)
+
+// abort 以统一的 JSON 结构终止请求，同时返回真实的 HTTP 状态码
+func abort(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"code":    status,
+		"message": message,
+	})
+}
+
+// JWTAuth 校验 Authorization: Bearer <token>，
+// 通过后将 user_id / username / role 写入 gin 上下文。
+// 用户名和角色以数据库中的账号为准：账号被删除后令牌随即失效，修改角色后立即按新角色授权。
+func JWTAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			abort(c, http.StatusUnauthorized, "未登录或缺少认证令牌")
+			return
+		}
+
+		parts := strings.SplitN(header, " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || strings.TrimSpace(parts[1]) == "" {
+			abort(c, http.StatusUnauthorized, "认证令牌格式错误")
+			return
+		}
+
+		claims, err := utils.ParseToken(strings.TrimSpace(parts[1]))
+		if err != nil || claims == nil {
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				abort(c, http.StatusUnauthorized, "认证令牌已过期，请重新登录")
+				return
+			}
+			abort(c, http.StatusUnauthorized, "无效的认证令牌")
+			return
+		}
+
+		var user models.User
+		if err := database.GetDB().Select("id", "username", "role").First(&user, claims.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				abort(c, http.StatusUnauthorized, "账号不存在或已被删除，请重新登录")
+				return
+			}
+			abort(c, http.StatusInternalServerError, "查询用户失败")
+			return
+		}
+
+		c.Set("user_id", user.ID)
+		c.Set("username", user.Username)
+		c.Set("role", user.Role)
+
+		// 审计日志的操作者
+		actor := audit.ActorFrom(c.Request.Context())
+		actor.UserID, actor.Username, actor.Source = user.ID, user.Username, models.AuditSourceAPI
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), actor))
+		c.Next()
+	}
+}
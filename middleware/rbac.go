@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Policy 声明式路由访问策略，键为 "METHOD /完整/路由"，例如 "PUT /api/transfers/:id/approve"
+type Policy map[string]models.Permission
+
+// Authorize 按路由策略校验当前用户角色的权限，必须挂在 JWTAuth 之后。
+// 未在策略中声明的路由一律拒绝，避免新接口忘记配置权限时被公开。
+func Authorize(policy Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		perm, ok := policy[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			abort(c, http.StatusForbidden, "该接口未配置访问策略")
+			return
+		}
+
+		if !models.HasPermission(c.GetInt("role"), perm) {
+			abort(c, http.StatusForbidden, "权限不足")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader 请求ID的请求/响应头
+const RequestIDHeader = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID 为每个请求分配请求ID（客户端传入合法的 X-Request-ID 时沿用），
+// 写入响应头和 gin 上下文，并作为审计日志的关联ID
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+		ctx := audit.WithActor(c.Request.Context(), audit.Actor{RequestID: id, Source: models.AuditSourceAPI})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// newRequestID 生成 16 位十六进制随机ID
+func newRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,61 @@
+// models/approval.go
+package models
+
+import "time"
+
+// 审批步骤的审批人类型，在配置 approval.chains 中按调动类型组合成审批链
+const (
+	ApproverFromDeptManager = "from_dept_manager" // 调出部门主管 (未指定调出部门时取员工当前部门)
+	ApproverToDeptManager   = "to_dept_manager"   // 调入部门主管 (无调入部门的调动跳过此步)
+	ApproverHR              = "hr"                // 人事专员
+	ApproverRole            = "approver"          // 审批人 (拥有审批权限的用户)
+	ApproverAdmin           = "admin"             // 管理员
+)
+
+// ApprovalStepStatus 审批步骤状态枚举
+const (
+	ApprovalStepPending  = 1 // 待审批
+	ApprovalStepApproved = 2 // 已通过
+	ApprovalStepRejected = 3 // 已驳回
+	ApprovalStepSkipped  = 4 // 未执行 (前序步骤被驳回)
+)
+
+// TransferApprovalStep 调动审批链中的一个步骤
+type TransferApprovalStep struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	TransferID   uint       `gorm:"not null;index" json:"transfer_id"`
+	StepNo       int        `gorm:"not null" json:"step_no"`               // 从 1 开始
+	ApproverType string     `gorm:"size:50;not null" json:"approver_type"` // 审批人类型
+	DeptID       *uint      `json:"dept_id"`                               // 部门主管步骤对应的部门
+	Dept         Department `gorm:"foreignKey:DeptID" json:"dept,omitempty"`
+	Status       int        `gorm:"not null;default:1" json:"status"`
+	ActorID      uint       `json:"actor_id"` // 实际审批的用户
+	Actor        User       `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+	Comment      string     `gorm:"type:text" json:"comment"` // 审批意见
+	ActedAt      *time.Time `json:"acted_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// IsValidApproverType 判断审批人类型是否合法
+func IsValidApproverType(t string) bool {
+	switch t {
+	case ApproverFromDeptManager, ApproverToDeptManager, ApproverHR, ApproverRole, ApproverAdmin:
+		return true
+	}
+	return false
+}
+
+// GetApproverTypeText 获取审批人类型文本
+func GetApproverTypeText(t string) string {
+	textMap := map[string]string{
+		ApproverFromDeptManager: "调出部门主管",
+		ApproverToDeptManager:   "调入部门主管",
+		ApproverHR:              "人事专员",
+		ApproverRole:            "审批人",
+		ApproverAdmin:           "管理员",
+	}
+	if text, ok := textMap[t]; ok {
+		return text
+	}
+	return "未知"
+}
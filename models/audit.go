@@ -0,0 +1,83 @@
+// models/audit.go
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 审计对象
+const (
+	AuditEntityEmployee   = "employee"
+	AuditEntityDepartment = "department"
+	AuditEntityTransfer   = "transfer"
+	AuditEntityUser       = "user"
+)
+
+// 审计动作
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore" // 恢复已删除的记录
+	AuditActionPurge   = "purge"   // 超过保留期限后彻底清除
+)
+
+// 操作来源
+const (
+	AuditSourceAPI       = "api"       // 接口请求
+	AuditSourceScheduler = "scheduler" // 后台定时任务
+	AuditSourceSystem    = "system"    // 启动初始化、命令行等
+)
+
+// AuditChange 单个字段的变更
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// AuditChanges 字段名 -> 变更，以 JSON 文本存储
+type AuditChanges map[string]AuditChange
+
+// Value 实现 driver.Valuer
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(c)
+	return string(data), err
+}
+
+// Scan 实现 sql.Scanner
+func (c *AuditChanges) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = AuditChanges{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法解析审计变更: %T", value)
+	}
+	return json.Unmarshal(data, c)
+}
+
+// AuditLog 审计日志，记录谁在什么时候把哪个对象的哪些字段从什么改成了什么
+type AuditLog struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	Entity     string       `gorm:"size:50;not null;index:idx_audit_entity" json:"entity"`
+	EntityID   uint         `gorm:"not null;index:idx_audit_entity" json:"entity_id"`
+	Action     string       `gorm:"size:20;not null" json:"action"`
+	ActorID    uint         `gorm:"index" json:"actor_id"` // 操作用户，0 表示系统
+	ActorName  string       `gorm:"size:50" json:"actor_name"`
+	Source     string       `gorm:"size:20" json:"source"`
+	RequestID  string       `gorm:"size:64;index" json:"request_id"`
+	TransferID *uint        `gorm:"index" json:"transfer_id"` // 由调动生效引起的员工变更
+	Changes    AuditChanges `gorm:"type:text" json:"changes"`
+	CreatedAt  time.Time    `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,31 @@
+// models/backup.go
+package models
+
+import "time"
+
+// 备份触发方式
+const (
+	BackupTriggerScheduled = "scheduled" // 定时任务 (backup.schedule)
+	BackupTriggerManual    = "manual"    // 管理员在维护页面手动执行
+)
+
+// 备份执行结果
+const (
+	BackupStatusSuccess = "success"
+	BackupStatusFailed  = "failed"
+)
+
+// BackupRun 一次写入备份目录的完整备份
+type BackupRun struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Trigger    string     `gorm:"size:20;not null" json:"trigger"`
+	Status     string     `gorm:"size:20;not null;index" json:"status"`
+	FileName   string     `gorm:"size:255" json:"file_name"` // 备份目录下的文件名
+	Size       int64      `json:"size"`                      // 文件大小 (字节)
+	SHA256     string     `gorm:"column:sha256;size:64" json:"sha256"`
+	DurationMs int64      `json:"duration_ms"`            // 耗时 (毫秒)
+	Error      string     `gorm:"type:text" json:"error"` // 失败原因
+	PrunedAt   *time.Time `json:"pruned_at"`              // 按保留策略删除文件的时间
+	StartedAt  time.Time  `gorm:"index" json:"started_at"`
+	FinishedAt time.Time  `json:"finished_at"`
+}
@@ -1,22 +1,30 @@
 // models/department.go
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Department struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	DeptNo    string    `gorm:"size:20;uniqueIndex;not null" json:"dept_no"`   // 部门编号
-	Name      string    `gorm:"size:100;not null" json:"name"`                 // 部门名称
-	ManagerID uint      `json:"manager_id"`                                    // 部门主管ID (关联员工)
-	Manager   Employee  `gorm:"foreignKey:ManagerID" json:"manager,omitempty"` // 主管信息
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	DeptNo    string         `gorm:"size:20;uniqueIndex;not null" json:"dept_no"`   // 部门编号
+	Name      string         `gorm:"size:100;not null" json:"name"`                 // 部门名称
+	ManagerID uint           `json:"manager_id"`                                    // 部门主管ID (关联员工)
+	ParentID  *uint          `gorm:"index" json:"parent_id"`                        // 上级部门，为空表示顶级部门
+	Version   uint           `gorm:"not null;default:1" json:"version"`             // 版本号，每次修改加一 (乐观锁)
+	Manager   Employee       `gorm:"foreignKey:ManagerID" json:"manager,omitempty"` // 主管信息
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"` // 软删除时间
 }
 
 type CreateDepartmentRequest struct {
 	DeptNo    string `json:"dept_no" binding:"required"`
 	Name      string `json:"name" binding:"required"`
 	ManagerID uint   `json:"manager_id"`
+	ParentID  uint   `json:"parent_id"` // 上级部门，0 表示顶级部门
 }
 
 type UpdateDepartmentRequest struct {
@@ -24,3 +32,59 @@ type UpdateDepartmentRequest struct {
 	Name      string `json:"name" binding:"required"`
 	ManagerID uint   `json:"manager_id"`
 }
+
+// MoveDepartmentRequest 移动部门 (连同下级部门) 到新的上级部门下
+type MoveDepartmentRequest struct {
+	ParentID uint `json:"parent_id"` // 0 表示移为顶级部门
+}
+
+// MergeDepartmentRequest 把部门合并到另一个部门
+type MergeDepartmentRequest struct {
+	TargetDeptID uint   `json:"target_dept_id" binding:"required"` // 合并到的部门
+	Reason       string `json:"reason"`
+}
+
+// SplitDepartmentRequest 按员工名单拆分部门
+type SplitDepartmentRequest struct {
+	Parts   []SplitPart `json:"parts" binding:"required,min=1,dive"`
+	Archive bool        `json:"archive"` // 拆分后归档原部门，要求原部门全部员工都已分配
+	Reason  string      `json:"reason"`
+}
+
+// SplitPart 拆分出的一个部门：DeptID 指定已有部门，否则按 DeptNo、Name 新建 (与原部门同一上级)
+type SplitPart struct {
+	DeptID      uint   `json:"dept_id"`
+	DeptNo      string `json:"dept_no"`
+	Name        string `json:"name"`
+	ManagerID   uint   `json:"manager_id"` // 新部门主管，不填时原部门主管随其所在部分转入
+	EmployeeIDs []uint `json:"employee_ids" binding:"required,min=1"`
+}
+
+// DepartmentNode 部门树中的一个节点
+type DepartmentNode struct {
+	ID             uint              `json:"id"`
+	DeptNo         string            `json:"dept_no"`
+	Name           string            `json:"name"`
+	ManagerID      uint              `json:"manager_id"`
+	ParentID       *uint             `json:"parent_id"`
+	Headcount      int               `json:"headcount"`       // 本部门的在岗人数
+	TotalHeadcount int               `json:"total_headcount"` // 含全部下级部门的在岗人数
+	Children       []*DepartmentNode `json:"children"`
+}
+
+// DepartmentHeadcount 部门在某一日期的在岗人数
+type DepartmentHeadcount struct {
+	DepartmentID uint   `json:"department_id"`
+	DeptNo       string `json:"dept_no"`
+	Name         string `json:"name"`
+	Headcount    int    `json:"headcount"`
+}
+
+// HeadcountReport 某一日期的组织人数统计
+type HeadcountReport struct {
+	AsOf        string                `json:"as_of"`
+	Departments []DepartmentHeadcount `json:"departments"`
+	Unassigned  int                   `json:"unassigned"` // 未分配部门的人数
+	Total       int                   `json:"total"`
+	Warnings    []string              `json:"warnings,omitempty"` // 早期数据无法准确还原的说明
+}
@@ -3,6 +3,8 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // EmployeeStatus 员工状态枚举
@@ -19,21 +21,24 @@ const (
 
 // Employee 员工模型
 type Employee struct {
-	ID          uint       `gorm:"primaryKey" json:"id"`
-	EmployeeID  string     `gorm:"column:employee_id;size:20;uniqueIndex;not null" json:"employee_id"`
-	Name        string     `gorm:"size:50;not null" json:"name"`
-	Status      int        `gorm:"not null;default:1" json:"status"` // 使用int而不是EmployeeStatus，便于JSON序列化
-	ArrivalDate string     `gorm:"type:date;not null" json:"arrival_date"`
-	JobTitle    string     `gorm:"size:100" json:"job_title"`
-	Position    string     `gorm:"size:100" json:"position"`
-	Department  string     `gorm:"size:100" json:"department"` // 先简单处理，后面再关联
-	Phone       string     `gorm:"size:20" json:"phone"`
-	Email       string     `gorm:"size:100" json:"email"`
-	Address     string     `gorm:"type:text" json:"address"`
-	Remark      string     `gorm:"type:text" json:"remark"`
-	Transfers   []Transfer `gorm:"foreignKey:EmployeeID;constraint:-" json:"-"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	EmployeeID   string         `gorm:"column:employee_id;size:20;uniqueIndex;not null" json:"employee_id"`
+	Name         string         `gorm:"size:50;not null" json:"name"`
+	Status       int            `gorm:"not null;default:1" json:"status"` // 使用int而不是EmployeeStatus，便于JSON序列化
+	ArrivalDate  string         `gorm:"type:date;not null" json:"arrival_date"`
+	JobTitle     string         `gorm:"size:100" json:"job_title"`
+	Position     string         `gorm:"size:100" json:"position"`
+	DepartmentID *uint          `gorm:"index" json:"department_id"` // 所在部门
+	Department   *Department    `gorm:"foreignKey:DepartmentID;constraint:-" json:"department,omitempty"`
+	Phone        string         `gorm:"size:20" json:"phone"`
+	Email        string         `gorm:"size:100" json:"email"`
+	Address      string         `gorm:"type:text" json:"address"`
+	Remark       string         `gorm:"type:text" json:"remark"`
+	Version      uint           `gorm:"not null;default:1" json:"version"` // 版本号，每次修改加一 (乐观锁)
+	Transfers    []Transfer     `gorm:"foreignKey:EmployeeID;constraint:-" json:"-"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at"` // 软删除时间
 }
 
 // TableName 指定表名
@@ -43,47 +48,59 @@ func (Employee) TableName() string {
 
 // 请求和响应DTO
 type CreateEmployeeRequest struct {
-	EmployeeID  string `json:"employee_id" binding:"required"`
-	Name        string `json:"name" binding:"required"`
-	Status      int    `json:"status" binding:"required,min=1,max=6"`
-	ArrivalDate string `json:"arrival_date" binding:"required"`
-	JobTitle    string `json:"job_title"`
-	Position    string `json:"position"`
-	Department  string `json:"department"`
-	Phone       string `json:"phone"`
-	Email       string `json:"email"`
-	Address     string `json:"address"`
-	Remark      string `json:"remark"`
+	EmployeeID   string `json:"employee_id" binding:"required"`
+	Name         string `json:"name" binding:"required"`
+	Status       int    `json:"status" binding:"required,min=1,max=6"`
+	ArrivalDate  string `json:"arrival_date" binding:"required"`
+	JobTitle     string `json:"job_title"`
+	Position     string `json:"position"`
+	DepartmentID uint   `json:"department_id"`
+	Phone        string `json:"phone"`
+	Email        string `json:"email"`
+	Address      string `json:"address"`
+	Remark       string `json:"remark"`
 }
 
 type UpdateEmployeeRequest struct {
-	Name       string `json:"name"`
-	Status     int    `json:"status" binding:"min=1,max=6"`
-	JobTitle   string `json:"job_title"`
-	Position   string `json:"position"`
-	Department string `json:"department"`
-	Phone      string `json:"phone"`
-	Email      string `json:"email"`
-	Address    string `json:"address"`
-	Remark     string `json:"remark"`
+	Name         string `json:"name"`
+	Status       int    `json:"status" binding:"min=1,max=6"`
+	JobTitle     string `json:"job_title"`
+	Position     string `json:"position"`
+	DepartmentID uint   `json:"department_id"`
+	Phone        string `json:"phone"`
+	Email        string `json:"email"`
+	Address      string `json:"address"`
+	Remark       string `json:"remark"`
 }
 
 type EmployeeResponse struct {
-	ID          uint      `json:"id"`
-	EmployeeID  string    `json:"employee_id"`
-	Name        string    `json:"name"`
-	Status      int       `json:"status"`
-	StatusText  string    `json:"status_text"`
-	ArrivalDate string    `json:"arrival_date"`
-	JobTitle    string    `json:"job_title"`
-	Position    string    `json:"position"`
-	Department  string    `json:"department"`
-	Phone       string    `json:"phone"`
-	Email       string    `json:"email"`
-	Address     string    `json:"address"`
-	Remark      string    `json:"remark"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           uint       `json:"id"`
+	EmployeeID   string     `json:"employee_id"`
+	Name         string     `json:"name"`
+	Status       int        `json:"status"`
+	StatusText   string     `json:"status_text"`
+	ArrivalDate  string     `json:"arrival_date"`
+	JobTitle     string     `json:"job_title"`
+	Position     string     `json:"position"`
+	DepartmentID *uint      `json:"department_id"`
+	DepartmentNo string     `json:"department_no"`
+	Department   string     `json:"department"` // 部门名称
+	Phone        string     `json:"phone"`
+	Email        string     `json:"email"`
+	Address      string     `json:"address"`
+	Remark       string     `json:"remark"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"` // 已删除员工的删除时间
+	Version      uint       `json:"version"`
+}
+
+// EmployeeAsOfResponse 员工在某一日期的信息 (部门、职位、职务、状态按调动和修改记录还原)
+type EmployeeAsOfResponse struct {
+	AsOf     string           `json:"as_of"`
+	Employed bool             `json:"employed"` // 该日期是否已入职
+	Employee EmployeeResponse `json:"employee"`
+	Warnings []string         `json:"warnings,omitempty"` // 早期数据无法准确还原的说明
 }
 
 // 获取状态文本
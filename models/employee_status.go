@@ -0,0 +1,68 @@
+// models/employee_status.go
+package models
+
+// StatusTransition 员工状态的一种合法变更
+type StatusTransition struct {
+	To  EmployeeStatus
+	Via int // 非 0 时只能通过该类型的调动审批生效，不能直接修改员工档案
+}
+
+// statusTransitions 员工状态变更表: 当前状态 -> 允许变更到的状态
+var statusTransitions = map[EmployeeStatus][]StatusTransition{
+	StatusProbation: {
+		{To: StatusActive, Via: TransferTypeConfirmation},
+		{To: StatusResigned, Via: TransferTypeResignation},
+	},
+	StatusActive: {
+		{To: StatusPartTime, Via: TransferTypePartTime},
+		{To: StatusResigned, Via: TransferTypeResignation},
+		{To: StatusRetired, Via: TransferTypeRetirement},
+	},
+	StatusPartTime: {
+		{To: StatusActive}, // 恢复全职
+		{To: StatusResigned, Via: TransferTypeResignation},
+		{To: StatusRetired, Via: TransferTypeRetirement},
+	},
+	StatusResigned: {
+		{To: StatusRehired, Via: TransferTypeRehire},
+	},
+	StatusRehired: {
+		{To: StatusActive},
+		{To: StatusPartTime, Via: TransferTypePartTime},
+		{To: StatusResigned, Via: TransferTypeResignation},
+		{To: StatusRetired, Via: TransferTypeRetirement},
+	},
+	StatusRetired: {
+		{To: StatusRehired, Via: TransferTypeRehire}, // 退休返聘
+	},
+}
+
+// NextStatuses 获取从当前状态可以变更到的状态
+func NextStatuses(from int) []StatusTransition {
+	return append([]StatusTransition{}, statusTransitions[EmployeeStatus(from)]...)
+}
+
+// FindStatusTransition 查找 from -> to 的状态变更，不在变更表中时返回 false
+func FindStatusTransition(from, to int) (StatusTransition, bool) {
+	for _, t := range statusTransitions[EmployeeStatus(from)] {
+		if int(t.To) == to {
+			return t, true
+		}
+	}
+	return StatusTransition{}, false
+}
+
+// NextStatus 员工可变更到的一个状态
+type NextStatus struct {
+	Status           int    `json:"status"`
+	StatusText       string `json:"status_text"`
+	TransferType     int    `json:"transfer_type,omitempty"`      // 需通过该类型的调动审批生效，0 表示可直接修改
+	TransferTypeText string `json:"transfer_type_text,omitempty"` // 调动类型文本
+}
+
+// NextStatusesResponse 员工当前状态及可变更到的状态
+type NextStatusesResponse struct {
+	Status     int          `json:"status"`
+	StatusText string       `json:"status_text"`
+	Next       []NextStatus `json:"next"`
+}
@@ -0,0 +1,108 @@
+// models/role.go
+package models
+
+// 用户角色，对应 User.Role 字段
+const (
+	RoleViewer   = 1 // 普通用户（只读）
+	RoleAdmin    = 2 // 管理员
+	RoleHRClerk  = 3 // 人事专员
+	RoleApprover = 4 // 审批人
+)
+
+// Permission 权限标识
+type Permission string
+
+const (
+	PermProfile          Permission = "profile:read"      // 查看个人信息
+	PermEmployeeRead     Permission = "employee:read"     // 查看员工
+	PermEmployeeWrite    Permission = "employee:write"    // 新增/修改员工
+	PermEmployeeDelete   Permission = "employee:delete"   // 删除员工
+	PermDepartmentRead   Permission = "department:read"   // 查看部门
+	PermDepartmentWrite  Permission = "department:write"  // 新增/修改部门
+	PermDepartmentDelete Permission = "department:delete" // 删除部门
+	PermDepartmentReorg  Permission = "department:reorg"  // 部门合并、拆分 (批量生效调动并归档原部门)
+	PermTransferRead     Permission = "transfer:read"     // 查看调动记录
+	PermTransferCreate   Permission = "transfer:create"   // 提交调动申请
+	PermTransferApprove  Permission = "transfer:approve"  // 审批调动 (审批链中的"审批人"步骤)
+	PermTransferReview   Permission = "transfer:review"   // 参与审批链 (具体能否审批由审批步骤决定)
+	PermTransferRunJobs  Permission = "transfer:run-jobs" // 手动执行到期调动
+	PermTransferCancel   Permission = "transfer:cancel"   // 取消未生效的调动、撤销已生效的调动
+	PermBackup           Permission = "backup:manage"     // 系统维护与备份
+	PermBackupRestore    Permission = "backup:restore"    // 从备份恢复数据库 (覆盖现有数据)
+	PermAuditRead        Permission = "audit:read"        // 查看审计日志
+	PermDataPurge        Permission = "data:purge"        // 彻底清除超过保留期限的已删除记录
+	PermUserManage       Permission = "user:manage"       // 用户与角色管理
+)
+
+var readOnlyPermissions = []Permission{
+	PermProfile,
+	PermEmployeeRead,
+	PermDepartmentRead,
+	PermTransferRead,
+	PermTransferReview, // 部门主管可以是任意角色的用户
+}
+
+// rolePermissions 角色 -> 权限集合
+var rolePermissions = map[int][]Permission{
+	RoleViewer: readOnlyPermissions,
+	RoleHRClerk: append(append([]Permission{}, readOnlyPermissions...),
+		PermEmployeeWrite,
+		PermDepartmentWrite,
+		PermTransferCreate,
+		PermTransferCancel,
+	),
+	RoleApprover: append(append([]Permission{}, readOnlyPermissions...),
+		PermTransferApprove,
+	),
+	RoleAdmin: append(append([]Permission{}, readOnlyPermissions...),
+		PermEmployeeWrite,
+		PermEmployeeDelete,
+		PermDepartmentWrite,
+		PermDepartmentDelete,
+		PermDepartmentReorg,
+		PermTransferCreate,
+		PermTransferApprove,
+		PermTransferCancel,
+		PermTransferRunJobs,
+		PermBackup,
+		PermBackupRestore,
+		PermAuditRead,
+		PermDataPurge,
+		PermUserManage,
+	),
+}
+
+// IsValidRole 判断角色值是否合法
+func IsValidRole(role int) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission 判断角色是否拥有某项权限
+func HasPermission(role int, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPermissions 获取角色拥有的全部权限
+func GetPermissions(role int) []Permission {
+	return append([]Permission{}, rolePermissions[role]...)
+}
+
+// GetRoleText 获取角色文本
+func GetRoleText(role int) string {
+	roleMap := map[int]string{
+		RoleViewer:   "普通用户",
+		RoleAdmin:    "管理员",
+		RoleHRClerk:  "人事专员",
+		RoleApprover: "审批人",
+	}
+	if text, ok := roleMap[role]; ok {
+		return text
+	}
+	return "未知"
+}
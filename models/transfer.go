@@ -5,9 +5,13 @@ import "time"
 
 // TransferType 调动类型枚举
 const (
-	TransferTypeDepartment = 1 // 部门调动
-	TransferTypePosition   = 2 // 职位调动
-	TransferTypeRetirement = 3 // 离退休
+	TransferTypeDepartment   = 1 // 部门调动
+	TransferTypePosition     = 2 // 职位调动
+	TransferTypeRetirement   = 3 // 离退休
+	TransferTypeResignation  = 4 // 离职
+	TransferTypeRehire       = 5 // 返聘
+	TransferTypeConfirmation = 6 // 试用转正
+	TransferTypePartTime     = 7 // 转兼职
 )
 
 // TransferStatus 调动状态枚举
@@ -16,22 +20,70 @@ const (
 	TransferStatusApproved  = 2 // 已批准
 	TransferStatusRejected  = 3 // 已驳回
 	TransferStatusCompleted = 4 // 已完成
+	TransferStatusWithdrawn = 5 // 已撤回 (提交人在审批结束前撤回)
+	TransferStatusCancelled = 6 // 已取消 (生效前由人事取消)
+	TransferStatusReverted  = 7 // 已撤销 (生效后通过反向调动恢复)
 )
 
+// IsValidTransferType 判断调动类型是否合法
+func IsValidTransferType(t int) bool {
+	switch t {
+	case TransferTypeDepartment, TransferTypePosition, TransferTypeRetirement,
+		TransferTypeResignation, TransferTypeRehire, TransferTypeConfirmation, TransferTypePartTime:
+		return true
+	}
+	return false
+}
+
+// GetTransferTypeText 获取调动类型文本
+func GetTransferTypeText(t int) string {
+	typeMap := map[int]string{
+		TransferTypeDepartment:   "部门调动",
+		TransferTypePosition:     "职位调动",
+		TransferTypeRetirement:   "离退休",
+		TransferTypeResignation:  "离职",
+		TransferTypeRehire:       "返聘",
+		TransferTypeConfirmation: "试用转正",
+		TransferTypePartTime:     "转兼职",
+	}
+	if text, ok := typeMap[t]; ok {
+		return text
+	}
+	return "未知"
+}
+
 type Transfer struct {
-	ID           uint       `gorm:"primaryKey" json:"id"`
-	EmployeeID   uint       `gorm:"not null;index" json:"employee_id"`
-	Employee     Employee   `gorm:"foreignKey:EmployeeID;references:ID;constraint:-" json:"employee"`
-	TransferDate string     `gorm:"type:date;not null" json:"transfer_date"`
-	Type         int        `gorm:"not null" json:"type"`
-	FromDeptID   *uint      `json:"from_dept_id"`
-	FromDept     Department `gorm:"foreignKey:FromDeptID" json:"from_dept"`
-	ToDeptID     *uint      `json:"to_dept_id"`
-	ToDept       Department `gorm:"foreignKey:ToDeptID" json:"to_dept"`
-	Reason       string     `gorm:"type:text" json:"reason"`
-	Status       int        `gorm:"not null;default:1" json:"status"`
-	ApproverID   uint       `json:"approver_id"`
-	Approver     User       `gorm:"foreignKey:ApproverID" json:"approver,omitempty"`
-	ApprovedAt   *time.Time `json:"approved_at"`
-	CreatedAt    time.Time  `json:"created_at"`
+	ID                uint                   `gorm:"primaryKey" json:"id"`
+	EmployeeID        uint                   `gorm:"not null;index" json:"employee_id"`
+	Employee          Employee               `gorm:"foreignKey:EmployeeID;references:ID;constraint:-" json:"employee"`
+	TransferDate      string                 `gorm:"type:date;not null" json:"transfer_date"`
+	Type              int                    `gorm:"not null" json:"type"`
+	FromDeptID        *uint                  `json:"from_dept_id"`
+	FromDept          Department             `gorm:"foreignKey:FromDeptID" json:"from_dept"`
+	ToDeptID          *uint                  `json:"to_dept_id"`
+	ToDept            Department             `gorm:"foreignKey:ToDeptID" json:"to_dept"`
+	FromPosition      string                 `gorm:"size:100" json:"from_position"`                  // 原职位 (职位调动)
+	ToPosition        string                 `gorm:"size:100" json:"to_position"`                    // 新职位 (职位调动)
+	FromJobTitle      string                 `gorm:"size:100" json:"from_job_title"`                 // 原职务 (职位调动)
+	ToJobTitle        string                 `gorm:"size:100" json:"to_job_title"`                   // 新职务 (职位调动)
+	LastWorkingDay    *string                `gorm:"type:date" json:"last_working_day,omitempty"`    // 最后工作日 (离职)
+	ContractStartDate *string                `gorm:"type:date" json:"contract_start_date,omitempty"` // 返聘合同开始日期
+	ContractEndDate   *string                `gorm:"type:date" json:"contract_end_date,omitempty"`   // 返聘合同结束日期
+	Reason            string                 `gorm:"type:text" json:"reason"`
+	Status            int                    `gorm:"not null;default:1" json:"status"`
+	SubmitterID       uint                   `gorm:"index" json:"submitter_id"` // 提交人 (登录用户)
+	Submitter         User                   `gorm:"foreignKey:SubmitterID" json:"submitter,omitempty"`
+	ApproverID        uint                   `json:"approver_id"`
+	Approver          User                   `gorm:"foreignKey:ApproverID" json:"approver,omitempty"`
+	ApprovedAt        *time.Time             `json:"approved_at"`
+	CompletedAt       *time.Time             `json:"completed_at"`                          // 生效 (写入员工档案) 时间
+	FromStatus        int                    `gorm:"not null;default:0" json:"from_status"` // 生效前的员工状态，生效时记录
+	ToStatus          int                    `gorm:"not null;default:0" json:"to_status"`   // 生效后的员工状态，生效时记录
+	RevertOfID        *uint                  `gorm:"index" json:"revert_of_id"`             // 反向调动：被撤销的原调动ID
+	CancelledByID     uint                   `json:"cancelled_by_id"`                       // 撤回/取消/撤销的操作人
+	CancelledAt       *time.Time             `json:"cancelled_at"`
+	CancelReason      string                 `gorm:"type:text" json:"cancel_reason"`
+	Version           uint                   `gorm:"not null;default:1" json:"version"`            // 版本号，每次修改加一 (乐观锁)
+	Steps             []TransferApprovalStep `gorm:"foreignKey:TransferID" json:"steps,omitempty"` // 审批链
+	CreatedAt         time.Time              `json:"created_at"`
 }
@@ -7,16 +7,18 @@ import (
 
 // User 用户模型
 type User struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Username  string    `gorm:"size:50;unique;not null" json:"username"`
-	Password  string    `gorm:"size:255;not null" json:"-"`
-	Role      int       `gorm:"not null;default:1" json:"role"` // 1-普通用户，2-管理员
-	RealName  string    `gorm:"size:50" json:"real_name"`
-	Email     string    `gorm:"size:100" json:"email"`
-	Phone     string    `gorm:"size:20" json:"phone"`
-	LastLogin time.Time `json:"last_login"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Username   string    `gorm:"size:50;unique;not null" json:"username"`
+	Password   string    `gorm:"size:255;not null" json:"-"`
+	Role       int       `gorm:"not null;default:1" json:"role"` // 1-普通用户，2-管理员，3-人事专员，4-审批人
+	RealName   string    `gorm:"size:50" json:"real_name"`
+	Email      string    `gorm:"size:100" json:"email"`
+	Phone      string    `gorm:"size:20" json:"phone"`
+	EmployeeID *uint     `gorm:"uniqueIndex" json:"employee_id"` // 关联的员工档案，用于部门主管审批
+	Employee   *Employee `gorm:"foreignKey:EmployeeID;constraint:-" json:"employee,omitempty"`
+	LastLogin  time.Time `json:"last_login"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
 
 // LoginRequest 登录请求
@@ -26,15 +28,28 @@ type LoginRequest struct {
 }
 
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
-	RealName string `json:"real_name"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
+	Username   string `json:"username" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+	RealName   string `json:"real_name"`
+	Email      string `json:"email"`
+	Phone      string `json:"phone"`
+	Role       int    `json:"role"`        // 不填默认为普通用户
+	EmployeeID uint   `json:"employee_id"` // 关联的员工档案，可不填
+}
+
+// UpdateUserRoleRequest 修改用户角色请求
+type UpdateUserRoleRequest struct {
+	Role int `json:"role" binding:"required"`
 }
 
+// UpdateUserEmployeeRequest 关联员工档案请求，employee_id 为 0 表示解除关联
+type UpdateUserEmployeeRequest struct {
+	EmployeeID uint `json:"employee_id"`
+}
+
 // LoginResponse 登录响应
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token       string       `json:"token"`
+	User        User         `json:"user"`
+	Permissions []Permission `json:"permissions"`
 }
@@ -0,0 +1,78 @@
+// policy.go
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/middleware"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/gin-gonic/gin"
+)
+
+// routePolicy 每个受保护接口所需的权限。
+// 新增路由时必须在此登记，否则 Authorize 中间件会直接拒绝访问。
+var routePolicy = middleware.Policy{
+	// --- 认证与用户 ---
+	"POST /api/register":          models.PermUserManage,
+	"GET /api/profile":            models.PermProfile,
+	"GET /api/users":              models.PermUserManage,
+	"PUT /api/users/:id/role":     models.PermUserManage,
+	"PUT /api/users/:id/employee": models.PermUserManage,
+
+	// --- 员工管理 ---
+	"GET /api/employees":                   models.PermEmployeeRead,
+	"GET /api/employees/:id":               models.PermEmployeeRead,
+	"GET /api/employees/:id/as-of":         models.PermEmployeeRead,
+	"GET /api/employees/:id/next-statuses": models.PermEmployeeRead,
+	"POST /api/employees":                  models.PermEmployeeWrite,
+	"POST /api/employees/import":           models.PermEmployeeWrite,
+	"PUT /api/employees/:id":               models.PermEmployeeWrite,
+	"DELETE /api/employees/:id":            models.PermEmployeeDelete,
+	"PUT /api/employees/:id/restore":       models.PermEmployeeDelete,
+
+	// --- 部门管理 ---
+	"GET /api/departments":             models.PermDepartmentRead,
+	"GET /api/departments/headcount":   models.PermDepartmentRead,
+	"GET /api/departments/tree":        models.PermDepartmentRead,
+	"POST /api/departments":            models.PermDepartmentWrite,
+	"PUT /api/departments/:id":         models.PermDepartmentWrite,
+	"PUT /api/departments/:id/move":    models.PermDepartmentWrite,
+	"DELETE /api/departments/:id":      models.PermDepartmentDelete,
+	"PUT /api/departments/:id/restore": models.PermDepartmentDelete,
+	"POST /api/departments/:id/merge":  models.PermDepartmentReorg,
+	"POST /api/departments/:id/split":  models.PermDepartmentReorg,
+
+	// --- 调动管理 ---
+	"POST /api/transfers":               models.PermTransferCreate,
+	"POST /api/transfers/batch":         models.PermTransferCreate,
+	"GET /api/transfers":                models.PermTransferRead,
+	"PUT /api/transfers/:id/approve":    models.PermTransferReview, // 具体步骤的审批资格由审批链校验
+	"PUT /api/transfers/approve-batch":  models.PermTransferReview,
+	"GET /api/transfers/my-approvals":   models.PermTransferReview,
+	"PUT /api/transfers/:id/withdraw":   models.PermTransferCreate, // 仅限提交人本人
+	"PUT /api/transfers/:id/cancel":     models.PermTransferCancel,
+	"POST /api/transfers/:id/revert":    models.PermTransferCancel,
+	"POST /api/transfers/run-scheduled": models.PermTransferRunJobs,
+
+	// --- 系统维护 ---
+	"GET /api/backup/export":      models.PermBackup,
+	"GET /api/backup/full":        models.PermBackup,
+	"POST /api/backup/restore":    models.PermBackupRestore,
+	"GET /api/backup/history":     models.PermBackup,
+	"POST /api/backup/run":        models.PermBackup,
+	"GET /api/audit":              models.PermAuditRead,
+	"POST /api/maintenance/purge": models.PermDataPurge,
+}
+
+// checkRoutePolicy 启动时检查受保护路由是否都登记了访问策略
+func checkRoutePolicy(routes gin.RoutesInfo) {
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, "/api/") || route.Path == "/api/login" {
+			continue
+		}
+		if _, ok := routePolicy[route.Method+" "+route.Path]; !ok {
+			log.Printf("⚠️ 路由 %s %s 未配置访问策略，将拒绝所有访问", route.Method, route.Path)
+		}
+	}
+}
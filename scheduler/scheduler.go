@@ -0,0 +1,86 @@
+// scheduler/scheduler.go
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/backup"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/service"
+	"github.com/robfig/cron/v3"
+)
+
+// StartTransferJob 在后台定期处理到期的已批准调动，启动时先执行一次。
+// interval <= 0 表示不启用；ctx 取消后退出。
+func StartTransferJob(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		log.Println("调动生效定时任务未启用")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		// 审计日志中记为定时任务操作
+		db := database.GetDB().WithContext(audit.WithActor(ctx, audit.Actor{Source: models.AuditSourceScheduler}))
+		for {
+			if _, err := service.RunDueTransfers(db, time.Now()); err != nil {
+				log.Printf("⚠️ 调动生效定时任务执行失败: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	log.Printf("✅ 调动生效定时任务已启动，间隔 %s", interval)
+}
+
+// backupStopTimeout 退出时等待正在执行的自动备份完成的最长时间
+const backupStopTimeout = time.Minute
+
+// StartBackupJob 按 cron 表达式定期把完整备份写入 dir，并按保留策略清理旧备份。
+// schedule 为空表示不启用；ctx 取消后不再触发新的备份，并等待正在执行的备份完成 (最多 backupStopTimeout)，
+// 之后关闭返回的通道，调用方应在进程退出前等待该通道。
+func StartBackupJob(ctx context.Context, schedule, dir string, keep backup.Retention, enc backup.Encryption) (<-chan struct{}, error) {
+	done := make(chan struct{})
+	if schedule == "" {
+		log.Println("自动备份未启用")
+		close(done)
+		return done, nil
+	}
+
+	db := database.GetDB()
+	c := cron.New()
+	_, err := c.AddFunc(schedule, func() {
+		if _, err := service.RunBackup(db, dir, models.BackupTriggerScheduled, keep, enc); err != nil {
+			log.Printf("⚠️ 自动备份执行失败: %v", err)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("backup.schedule 无效: %v", err)
+	}
+	c.Start()
+
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		select {
+		case <-c.Stop().Done():
+		case <-time.After(backupStopTimeout):
+			log.Printf("⚠️ 等待自动备份完成超时 (%s)，本次备份未完成，备份目录中可能遗留临时文件 (下次备份时删除)", backupStopTimeout)
+		}
+	}()
+
+	log.Printf("✅ 自动备份已启动，计划 %q，保存到 %s (加密: %t)", schedule, dir, enc.Enabled())
+	return done, nil
+}
@@ -0,0 +1,347 @@
+// service/approval.go
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+)
+
+// approvalChains 调动类型 -> 审批链，由 SetupApproval 根据配置设置
+var approvalChains = map[int][]string{}
+
+// defaultChain 未配置审批链的调动类型使用单级审批，与早期行为一致
+var defaultChain = []string{models.ApproverRole}
+
+// ErrNotApprover 当前用户不是当前审批步骤的审批人
+var ErrNotApprover = errors.New("当前审批步骤不由您审批")
+
+// ErrNotPending 调动已结束审批流程（或已被其他人处理）
+var ErrNotPending = errors.New("该记录已审批，无法重复操作")
+
+// SetupApproval 设置各调动类型的审批链
+func SetupApproval(chains map[int][]string) {
+	approvalChains = make(map[int][]string, len(chains))
+	for transferType, chain := range chains {
+		approvalChains[transferType] = append([]string{}, chain...)
+	}
+}
+
+// ApprovalChain 获取调动类型对应的审批链
+func ApprovalChain(transferType int) []string {
+	if chain, ok := approvalChains[transferType]; ok && len(chain) > 0 {
+		return chain
+	}
+	return defaultChain
+}
+
+// CreateApprovalSteps 按审批链为新提交的调动生成审批步骤，需在事务中调用。
+// 部门主管步骤在此确定对应的部门，主管本人在审批时按部门当前主管动态判断。
+func CreateApprovalSteps(tx *gorm.DB, transfer *models.Transfer) ([]models.TransferApprovalStep, error) {
+	fromDeptID := transfer.FromDeptID
+	if fromDeptID == nil {
+		var employee models.Employee
+		if err := tx.First(&employee, transfer.EmployeeID).Error; err != nil {
+			return nil, fmt.Errorf("员工不存在")
+		}
+		fromDeptID = employee.DepartmentID
+	}
+
+	var steps []models.TransferApprovalStep
+	for _, approverType := range ApprovalChain(transfer.Type) {
+		step := models.TransferApprovalStep{
+			TransferID:   transfer.ID,
+			ApproverType: approverType,
+			Status:       models.ApprovalStepPending,
+			CreatedAt:    transfer.CreatedAt,
+		}
+		switch approverType {
+		case models.ApproverFromDeptManager:
+			if fromDeptID == nil {
+				continue
+			}
+			step.DeptID = fromDeptID
+		case models.ApproverToDeptManager:
+			// 职位调动、离退休等没有调入部门，跳过
+			if transfer.ToDeptID == nil {
+				continue
+			}
+			step.DeptID = transfer.ToDeptID
+		}
+		step.StepNo = len(steps) + 1
+		steps = append(steps, step)
+	}
+
+	// 审批链全部被跳过时至少保留一级审批，避免未经审批直接通过
+	if len(steps) == 0 {
+		steps = append(steps, models.TransferApprovalStep{
+			TransferID:   transfer.ID,
+			StepNo:       1,
+			ApproverType: models.ApproverRole,
+			Status:       models.ApprovalStepPending,
+			CreatedAt:    transfer.CreatedAt,
+		})
+	}
+
+	if err := tx.Create(&steps).Error; err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// CurrentStep 获取调动当前待审批的步骤，审批流程已结束时返回 nil
+func CurrentStep(tx *gorm.DB, transferID uint) (*models.TransferApprovalStep, error) {
+	var step models.TransferApprovalStep
+	err := tx.Where("transfer_id = ? AND status = ?", transferID, models.ApprovalStepPending).
+		Order("step_no").First(&step).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &step, nil
+}
+
+// CanApproveStep 判断用户能否审批该步骤：
+//   - 任何人都不能审批自己提交的、或以自己为调动对象的申请；
+//   - 同一调动的各级审批须由不同的人完成，已处理过其中一步的用户不能再审批其他步骤；
+//   - 管理员只在该步骤没有其他可审批的人时代为审批 (例如部门未设置主管、主管未关联账号，
+//     或主管本人就是调动对象、已审批过前面的步骤)。
+func CanApproveStep(tx *gorm.DB, user *models.User, transfer *models.Transfer, step *models.TransferApprovalStep) (bool, error) {
+	var actors []uint
+	if err := tx.Model(&models.TransferApprovalStep{}).
+		Where("transfer_id = ? AND id <> ? AND actor_id <> 0", transfer.ID, step.ID).
+		Pluck("actor_id", &actors).Error; err != nil {
+		return false, err
+	}
+	eligible := func(u *models.User) bool {
+		if transfer.SubmitterID != 0 && transfer.SubmitterID == u.ID {
+			return false
+		}
+		if u.EmployeeID != nil && *u.EmployeeID == transfer.EmployeeID {
+			return false
+		}
+		for _, id := range actors {
+			if id == u.ID {
+				return false
+			}
+		}
+		return true
+	}
+	if !eligible(user) {
+		return false, nil
+	}
+
+	candidates, err := stepApprovers(tx, step)
+	if err != nil {
+		return false, err
+	}
+	found := false
+	for i := range candidates {
+		if !eligible(&candidates[i]) {
+			continue
+		}
+		if candidates[i].ID == user.ID {
+			return true, nil
+		}
+		found = true
+	}
+	if found {
+		return false, nil
+	}
+	// 该步骤没有可以审批的人，由管理员代为审批
+	return user.Role == models.RoleAdmin, nil
+}
+
+// approverRoles 拥有审批权限、可以处理"审批人"步骤的角色
+var approverRoles = func() []int {
+	var roles []int
+	for _, role := range []int{models.RoleViewer, models.RoleAdmin, models.RoleHRClerk, models.RoleApprover} {
+		if models.HasPermission(role, models.PermTransferApprove) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}()
+
+// stepApprovers 按步骤类型列出可以审批该步骤的用户：部门主管步骤为部门当前主管关联的账号
+func stepApprovers(tx *gorm.DB, step *models.TransferApprovalStep) ([]models.User, error) {
+	var users []models.User
+	switch step.ApproverType {
+	case models.ApproverFromDeptManager, models.ApproverToDeptManager:
+		if step.DeptID == nil {
+			return nil, nil
+		}
+		var dept models.Department
+		if err := tx.First(&dept, *step.DeptID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if dept.ManagerID == 0 {
+			return nil, nil
+		}
+		return users, tx.Where("employee_id = ?", dept.ManagerID).Find(&users).Error
+	case models.ApproverHR:
+		return users, tx.Where("role = ?", models.RoleHRClerk).Find(&users).Error
+	case models.ApproverRole:
+		return users, tx.Where("role IN ?", approverRoles).Find(&users).Error
+	case models.ApproverAdmin:
+		return users, tx.Where("role = ?", models.RoleAdmin).Find(&users).Error
+	}
+	return nil, nil
+}
+
+// ReviewResult 审批一个步骤后的结果
+type ReviewResult struct {
+	Step     models.TransferApprovalStep // 本次处理的步骤
+	Finished bool                        // 审批流程是否已结束 (驳回或最后一步通过)
+}
+
+// ReviewTransfer 处理调动当前的审批步骤，需在事务中调用。
+// 任一步驳回即结束流程；最后一步通过后调动变为"已批准"，其余情况保持"待审批"。
+func ReviewTransfer(tx *gorm.DB, transfer *models.Transfer, user *models.User, approve bool, comment string, now time.Time) (*ReviewResult, error) {
+	if transfer.Status != models.TransferStatusPending {
+		return nil, ErrNotPending
+	}
+
+	step, err := CurrentStep(tx, transfer.ID)
+	if err != nil {
+		return nil, err
+	}
+	if step == nil {
+		return nil, ErrNotPending
+	}
+
+	ok, err := CanApproveStep(tx, user, transfer, step)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotApprover
+	}
+
+	// 先按读取时的版本号占用调动记录，两人同时审批时后提交的一方返回 ErrVersionConflict
+	if err := UpdateVersioned(tx, &models.Transfer{}, transfer.ID, transfer.Version, nil); err != nil {
+		return nil, err
+	}
+	transfer.Version++
+
+	stepStatus := models.ApprovalStepApproved
+	if !approve {
+		stepStatus = models.ApprovalStepRejected
+	}
+
+	// 带状态条件更新，防止两人同时处理同一步骤
+	updated := tx.Model(&models.TransferApprovalStep{}).
+		Where("id = ? AND status = ?", step.ID, models.ApprovalStepPending).
+		Updates(map[string]interface{}{
+			"status":   stepStatus,
+			"actor_id": user.ID,
+			"comment":  comment,
+			"acted_at": now,
+		})
+	if updated.Error != nil {
+		return nil, updated.Error
+	}
+	if updated.RowsAffected == 0 {
+		return nil, ErrNotPending
+	}
+	step.Status = stepStatus
+	step.ActorID = user.ID
+	step.Comment = comment
+	step.ActedAt = &now
+
+	result := &ReviewResult{Step: *step}
+	before := *transfer
+
+	var transferStatus int
+	switch {
+	case !approve:
+		// 驳回：后续步骤不再执行
+		if err := tx.Model(&models.TransferApprovalStep{}).
+			Where("transfer_id = ? AND status = ?", transfer.ID, models.ApprovalStepPending).
+			Update("status", models.ApprovalStepSkipped).Error; err != nil {
+			return nil, err
+		}
+		transferStatus = models.TransferStatusRejected
+	default:
+		next, err := CurrentStep(tx, transfer.ID)
+		if err != nil {
+			return nil, err
+		}
+		if next != nil {
+			return result, recordReview(tx, before, *transfer, step)
+		}
+		// 最后一步通过前确认员工状态变更符合状态变更表
+		var employee models.Employee
+		if err := tx.First(&employee, transfer.EmployeeID).Error; err != nil {
+			return nil, fmt.Errorf("员工不存在")
+		}
+		if err := CheckTransferStatus(&employee, transfer); err != nil {
+			return nil, err
+		}
+		transferStatus = models.TransferStatusApproved
+	}
+
+	// 流程结束：记录最终状态及最后一步的审批人
+	if err := tx.Model(&models.Transfer{}).
+		Where("id = ? AND status = ?", transfer.ID, models.TransferStatusPending).
+		Updates(map[string]interface{}{
+			"status":      transferStatus,
+			"approver_id": user.ID,
+			"approved_at": now,
+		}).Error; err != nil {
+		return nil, err
+	}
+	transfer.Status = transferStatus
+	transfer.ApproverID = user.ID
+	transfer.ApprovedAt = &now
+	result.Finished = true
+	return result, recordReview(tx, before, *transfer, step)
+}
+
+// recordReview 记录审批操作的审计日志，审批步骤的变化记为 steps.<步骤号>.status 等字段
+func recordReview(tx *gorm.DB, before, after models.Transfer, step *models.TransferApprovalStep) error {
+	changes := audit.Diff(before, after)
+	prefix := fmt.Sprintf("steps.%d.", step.StepNo)
+	changes[prefix+"status"] = models.AuditChange{Old: models.ApprovalStepPending, New: step.Status}
+	changes[prefix+"actor_id"] = models.AuditChange{Old: uint(0), New: step.ActorID}
+	if step.Comment != "" {
+		changes[prefix+"comment"] = models.AuditChange{Old: "", New: step.Comment}
+	}
+	return audit.Record(tx, models.AuditEntityTransfer, after.ID, models.AuditActionUpdate, changes)
+}
+
+// PendingForUser 列出当前步骤可由该用户审批的调动
+func PendingForUser(db *gorm.DB, user *models.User) ([]models.Transfer, error) {
+	var transfers []models.Transfer
+	if err := db.Where("status = ?", models.TransferStatusPending).Order("created_at").Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+
+	mine := []models.Transfer{}
+	for i := range transfers {
+		step, err := CurrentStep(db, transfers[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		if step == nil {
+			continue
+		}
+		ok, err := CanApproveStep(db, user, &transfers[i], step)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			mine = append(mine, transfers[i])
+		}
+	}
+	return mine, nil
+}
@@ -0,0 +1,112 @@
+// service/approval_test.go
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+)
+
+// testUser 新建一个账号，employeeID 不为 nil 时关联员工档案
+func testUser(t *testing.T, role int, employeeID *uint) *models.User {
+	t.Helper()
+	user := &models.User{Username: fmt.Sprintf("user%05d", testSeq.Add(1)), Password: "x", Role: role, EmployeeID: employeeID}
+	if err := database.GetDB().Create(user).Error; err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	return user
+}
+
+// submitDepartmentTransfer 在 tx 中提交一条部门调动并按审批链生成审批步骤
+func submitDepartmentTransfer(t *testing.T, tx *gorm.DB, employee *models.Employee, to *models.Department) *models.Transfer {
+	t.Helper()
+	transfer := &models.Transfer{EmployeeID: employee.ID, Type: models.TransferTypeDepartment, TransferDate: "2024-06-01",
+		FromDeptID: employee.DepartmentID, ToDeptID: &to.ID, Status: models.TransferStatusPending, Version: 1}
+	if err := tx.Create(transfer).Error; err != nil {
+		t.Fatalf("创建调动失败: %v", err)
+	}
+	if _, err := CreateApprovalSteps(tx, transfer); err != nil {
+		t.Fatalf("生成审批步骤失败: %v", err)
+	}
+	return transfer
+}
+
+// review 审批当前步骤，返回审批流程是否已结束
+func review(t *testing.T, tx *gorm.DB, transfer *models.Transfer, user *models.User) (bool, error) {
+	t.Helper()
+	result, err := ReviewTransfer(tx, transfer, user, true, "", time.Now())
+	if err != nil {
+		return false, err
+	}
+	return result.Finished, nil
+}
+
+func setupTwoManagerChain(t *testing.T) {
+	SetupApproval(map[int][]string{
+		models.TransferTypeDepartment: {models.ApproverFromDeptManager, models.ApproverToDeptManager},
+	})
+	t.Cleanup(func() { SetupApproval(nil) })
+}
+
+// 两级审批都没有主管时由管理员代为审批，但同一管理员不能审批两级
+func TestApprovalAdminCannotApproveEveryStep(t *testing.T) {
+	setupTwoManagerChain(t)
+	from, to := testDepartment(t), testDepartment(t)
+	employee := testEmployee(t, int(models.StatusActive), &from.ID)
+	admin := testUser(t, models.RoleAdmin, nil)
+	otherAdmin := testUser(t, models.RoleAdmin, nil)
+
+	testTx(t, func(tx *gorm.DB) {
+		transfer := submitDepartmentTransfer(t, tx, employee, to)
+
+		if finished, err := review(t, tx, transfer, admin); err != nil || finished {
+			t.Fatalf("管理员审批第一级: finished = %v, err = %v，期望进入第二级", finished, err)
+		}
+		if _, err := review(t, tx, transfer, admin); !errors.Is(err, ErrNotApprover) {
+			t.Fatalf("同一管理员审批第二级: 期望 ErrNotApprover，得到 %v", err)
+		}
+		if finished, err := review(t, tx, transfer, otherAdmin); err != nil || !finished {
+			t.Fatalf("另一管理员审批第二级: finished = %v, err = %v，期望审批结束", finished, err)
+		}
+		if transfer.Status != models.TransferStatusApproved {
+			t.Errorf("调动状态 = %d，期望已批准", transfer.Status)
+		}
+	})
+}
+
+// 部门主管有账号时管理员不能越过主管审批；同时管理调出、调入部门的主管只能审批一级
+func TestApprovalManagerOfBothDepartments(t *testing.T) {
+	setupTwoManagerChain(t)
+	from, to := testDepartment(t), testDepartment(t)
+	managerEmployee := testEmployee(t, int(models.StatusActive), &from.ID)
+	for _, dept := range []*models.Department{from, to} {
+		if err := database.GetDB().Model(dept).Update("manager_id", managerEmployee.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	manager := testUser(t, models.RoleViewer, &managerEmployee.ID)
+	admin := testUser(t, models.RoleAdmin, nil)
+	employee := testEmployee(t, int(models.StatusActive), &from.ID)
+
+	testTx(t, func(tx *gorm.DB) {
+		transfer := submitDepartmentTransfer(t, tx, employee, to)
+
+		if _, err := review(t, tx, transfer, admin); !errors.Is(err, ErrNotApprover) {
+			t.Fatalf("管理员越过主管审批: 期望 ErrNotApprover，得到 %v", err)
+		}
+		if finished, err := review(t, tx, transfer, manager); err != nil || finished {
+			t.Fatalf("主管审批第一级: finished = %v, err = %v，期望进入第二级", finished, err)
+		}
+		if _, err := review(t, tx, transfer, manager); !errors.Is(err, ErrNotApprover) {
+			t.Fatalf("主管审批第二级: 期望 ErrNotApprover，得到 %v", err)
+		}
+		if finished, err := review(t, tx, transfer, admin); err != nil || !finished {
+			t.Fatalf("管理员代为审批第二级: finished = %v, err = %v，期望审批结束", finished, err)
+		}
+	})
+}
@@ -0,0 +1,214 @@
+// service/asof.go
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
+	"gorm.io/gorm"
+)
+
+// EmployeeAsOf 员工在某一日期 (当天结束时) 的状态
+type EmployeeAsOf struct {
+	Employee models.Employee // 还原后的员工信息 (部门、职位、职务、状态)
+	Employed bool            // 该日期是否已入职
+	Warnings []string        // 早期数据缺少原始信息、无法准确还原的说明
+}
+
+// asOfEvent 需要撤回的一次变更
+type asOfEvent struct {
+	at   time.Time
+	id   uint
+	undo func(emp *models.Employee) string // 返回非空时表示无法准确还原
+}
+
+// 按时间点还原时关注的员工字段
+var asOfFields = []string{"department_id", "position", "job_title", "status"}
+
+// EmployeesAsOf 还原员工在 date 当天结束时的部门、职位、职务和状态。
+// 以员工当前信息为起点，按时间倒序撤回 date 之后发生的变更：
+// 调动按生效日期 (transfer_date) 计，直接修改按审计日志的记录时间计。
+// 审计日志启用之前的直接修改无从得知，这部分无法还原。
+func EmployeesAsOf(db *gorm.DB, employees []models.Employee, date time.Time) ([]EmployeeAsOf, error) {
+	cutoff := nextDay(date)
+
+	ids := make([]uint, len(employees))
+	for i, emp := range employees {
+		ids[i] = emp.ID
+	}
+
+	events := make(map[uint][]asOfEvent, len(employees))
+
+	// 1. date 之后生效的调动 (含之后被撤销的调动及反向调动)
+	var transfers []models.Transfer
+	if err := db.Where("employee_id IN ? AND status IN ? AND transfer_date >= ?",
+		ids, []int{models.TransferStatusCompleted, models.TransferStatusReverted}, utils.FormatDate(cutoff)).
+		Find(&transfers).Error; err != nil {
+		return nil, fmt.Errorf("查询调动记录失败: %v", err)
+	}
+	for i := range transfers {
+		t := transfers[i]
+		at, err := utils.ParseDate(utils.DateOnly(t.TransferDate))
+		if err != nil {
+			continue
+		}
+		events[t.EmployeeID] = append(events[t.EmployeeID], asOfEvent{at: at, id: t.ID, undo: func(emp *models.Employee) string {
+			return undoTransfer(emp, &t)
+		}})
+	}
+
+	// 2. date 之后的直接修改 (调动生效引起的修改已在上面按生效日期处理)
+	var logs []models.AuditLog
+	if err := db.Where("entity = ? AND entity_id IN ? AND action = ? AND transfer_id IS NULL AND created_at >= ?",
+		models.AuditEntityEmployee, ids, models.AuditActionUpdate, cutoff).
+		Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("查询审计日志失败: %v", err)
+	}
+	for i := range logs {
+		l := logs[i]
+		events[l.EntityID] = append(events[l.EntityID], asOfEvent{at: l.CreatedAt, id: l.ID, undo: func(emp *models.Employee) string {
+			undoChanges(emp, l.Changes)
+			return ""
+		}})
+	}
+
+	results := make([]EmployeeAsOf, len(employees))
+	for i, emp := range employees {
+		list := events[emp.ID]
+		sort.Slice(list, func(a, b int) bool {
+			if !list[a].at.Equal(list[b].at) {
+				return list[a].at.After(list[b].at)
+			}
+			return list[a].id > list[b].id
+		})
+
+		result := EmployeeAsOf{Employee: emp}
+		for _, e := range list {
+			if warning := e.undo(&result.Employee); warning != "" {
+				result.Warnings = append(result.Warnings, warning)
+			}
+		}
+		if arrival, err := utils.ParseDate(utils.DateOnly(emp.ArrivalDate)); err == nil {
+			result.Employed = arrival.Before(cutoff)
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// undoTransfer 把员工信息恢复为调动生效前的值
+func undoTransfer(emp *models.Employee, t *models.Transfer) string {
+	// 迁移 0006 之前生效的调动没有记录生效前状态
+	recorded := t.ToStatus != 0
+
+	switch t.Type {
+	case models.TransferTypeDepartment:
+		if t.FromDeptID == nil && !recorded {
+			return fmt.Sprintf("调动 #%d 未记录原部门，部门无法还原", t.ID)
+		}
+		emp.DepartmentID = t.FromDeptID
+	case models.TransferTypePosition:
+		if t.ToPosition != "" || t.RevertOfID != nil {
+			emp.Position = t.FromPosition
+		}
+		if t.ToJobTitle != "" || t.RevertOfID != nil {
+			emp.JobTitle = t.FromJobTitle
+		}
+	default:
+		// 离退休、离职、返聘等改变员工状态的调动
+		if !recorded {
+			return fmt.Sprintf("调动 #%d 未记录原状态，状态无法还原", t.ID)
+		}
+		emp.Status = t.FromStatus
+	}
+	return ""
+}
+
+// undoChanges 按审计日志把字段恢复为修改前的值
+func undoChanges(emp *models.Employee, changes models.AuditChanges) {
+	for _, field := range asOfFields {
+		change, ok := changes[field]
+		if !ok {
+			continue
+		}
+		switch field {
+		case "department_id":
+			if id, ok := change.Old.(float64); ok {
+				deptID := uint(id)
+				emp.DepartmentID = &deptID
+			} else {
+				emp.DepartmentID = nil
+			}
+		case "position":
+			emp.Position, _ = change.Old.(string)
+		case "job_title":
+			emp.JobTitle, _ = change.Old.(string)
+		case "status":
+			if status, ok := change.Old.(float64); ok {
+				emp.Status = int(status)
+			}
+		}
+	}
+}
+
+// HeadcountAsOf 统计 date 当天结束时各部门的在岗人数 (已入职且未离职、未退休)。
+// 在该日期之后才删除的员工和部门仍计入。
+func HeadcountAsOf(db *gorm.DB, date time.Time) (*models.HeadcountReport, error) {
+	cutoff := nextDay(date)
+
+	var employees []models.Employee
+	if err := db.Unscoped().Where("deleted_at IS NULL OR deleted_at >= ?", cutoff).Find(&employees).Error; err != nil {
+		return nil, fmt.Errorf("查询员工失败: %v", err)
+	}
+	states, err := EmployeesAsOf(db, employees, date)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.HeadcountReport{AsOf: utils.FormatDate(date), Departments: []models.DepartmentHeadcount{}}
+	counts := make(map[uint]int)
+	for _, state := range states {
+		if !state.Employed {
+			continue
+		}
+		switch models.EmployeeStatus(state.Employee.Status) {
+		case models.StatusResigned, models.StatusRetired:
+			continue
+		}
+		report.Total++
+		if state.Employee.DepartmentID == nil {
+			report.Unassigned++
+		} else {
+			counts[*state.Employee.DepartmentID]++
+		}
+		report.Warnings = append(report.Warnings, state.Warnings...)
+	}
+
+	var depts []models.Department
+	if err := db.Unscoped().Where("deleted_at IS NULL OR deleted_at >= ?", cutoff).
+		Order("dept_no").Find(&depts).Error; err != nil {
+		return nil, fmt.Errorf("查询部门失败: %v", err)
+	}
+	for _, dept := range depts {
+		count := counts[dept.ID]
+		// 该日期之后才成立的部门不列出
+		if count == 0 && !dept.CreatedAt.Before(cutoff) {
+			continue
+		}
+		report.Departments = append(report.Departments, models.DepartmentHeadcount{
+			DepartmentID: dept.ID,
+			DeptNo:       dept.DeptNo,
+			Name:         dept.Name,
+			Headcount:    count,
+		})
+	}
+	return report, nil
+}
+
+// nextDay date 次日零点，即 date 当天结束的时刻
+func nextDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location()).AddDate(0, 0, 1)
+}
@@ -0,0 +1,46 @@
+// service/backup.go
+package service
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/backup"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+)
+
+// backupMu 防止定时备份与手动备份同时执行
+var backupMu sync.Mutex
+
+// BackupResult 一次备份的结果
+type BackupResult struct {
+	Run    *models.BackupRun `json:"run"`
+	Pruned []string          `json:"pruned"` // 按保留策略删除的旧备份文件
+}
+
+// RunBackup 把完整备份写入 dir (配置了 enc 时加密)，成功后按保留策略清理旧备份。
+// 无论成功与否都会在 backup_runs 表中留下记录；清理失败只记日志，不影响本次备份的结果。
+func RunBackup(db *gorm.DB, dir, trigger string, keep backup.Retention, enc backup.Encryption) (*BackupResult, error) {
+	backupMu.Lock()
+	defer backupMu.Unlock()
+
+	run, err := backup.RunToDir(db, dir, trigger, enc)
+	if err != nil {
+		log.Printf("⚠️ 备份失败: %v", err)
+		return &BackupResult{Run: run, Pruned: []string{}}, fmt.Errorf("备份失败: %v", err)
+	}
+	log.Printf("✅ 已备份到 %s (%d 字节，耗时 %dms)", run.FileName, run.Size, run.DurationMs)
+
+	result := &BackupResult{Run: run, Pruned: []string{}}
+	pruned, err := backup.Prune(db, dir, keep)
+	if err != nil {
+		log.Printf("⚠️ 清理旧备份失败: %v", err)
+	}
+	for _, p := range pruned {
+		log.Printf("已按保留策略删除备份 %s", p.FileName)
+		result.Pruned = append(result.Pruned, p.FileName)
+	}
+	return result, nil
+}
@@ -0,0 +1,187 @@
+// service/cancel.go
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
+	"gorm.io/gorm"
+)
+
+// ErrStatusChanged 调动状态已被其他操作改变（并发审批、定时任务生效等）
+var ErrStatusChanged = errors.New("调动状态已变化，请刷新后重试")
+
+// ErrRevertConflict 员工信息在调动生效后又被修改，无法安全地恢复
+var ErrRevertConflict = errors.New("员工信息在该调动生效后已发生变化，无法撤销")
+
+// ErrRevertUnknown 调动生效时没有记录原始信息（早期数据），无法自动恢复
+var ErrRevertUnknown = errors.New("该调动生效时未记录原始信息，无法自动撤销，请人工修改员工档案")
+
+// CloseTransfer 结束尚未生效的调动（撤回/取消），需在事务中调用。
+// 只有当前状态属于 from 且版本号与读取时一致才会更新，期间被审批或修改过时返回 ErrVersionConflict；
+// 未完成的审批步骤一并标记为未执行。
+func CloseTransfer(tx *gorm.DB, transfer *models.Transfer, from []int, to int, actorID uint, reason string, now time.Time) error {
+	before := *transfer
+	result := tx.Model(&models.Transfer{}).
+		Where("id = ? AND status IN ? AND version = ?", transfer.ID, from, transfer.Version).
+		Updates(map[string]interface{}{
+			"status":          to,
+			"cancelled_by_id": actorID,
+			"cancelled_at":    now,
+			"cancel_reason":   reason,
+			"version":         transfer.Version + 1,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	if err := tx.Model(&models.TransferApprovalStep{}).
+		Where("transfer_id = ? AND status = ?", transfer.ID, models.ApprovalStepPending).
+		Update("status", models.ApprovalStepSkipped).Error; err != nil {
+		return err
+	}
+
+	transfer.Status = to
+	transfer.Version++
+	transfer.CancelledByID = actorID
+	transfer.CancelledAt = &now
+	transfer.CancelReason = reason
+	return audit.Updated(tx, models.AuditEntityTransfer, transfer.ID, before, *transfer)
+}
+
+// RevertTransfer 撤销已生效的调动，需在事务中调用。
+// 生成一条立即生效的反向调动，把员工的部门/职位/状态恢复为原调动生效前的值，
+// 并将原调动标记为"已撤销"。员工信息在原调动生效后又被修改时拒绝撤销；
+// 原调出部门已归档或删除时返回 *ValidationError。
+func RevertTransfer(tx *gorm.DB, original *models.Transfer, actorID uint, reason string, now time.Time) (*models.Transfer, error) {
+	if original.Status != models.TransferStatusCompleted {
+		return nil, ErrStatusChanged
+	}
+	// 迁移 0006 之前生效的调动没有记录生效前后的状态 (ToStatus 为 0)：
+	// 部门、职位调动仍可按申请中的调出部门、原职位恢复，改变员工状态的调动无法恢复
+	if original.ToStatus == 0 {
+		switch {
+		case IsStatusTransfer(original.Type):
+			return nil, ErrRevertUnknown
+		case original.Type == models.TransferTypeDepartment && original.FromDeptID == nil:
+			return nil, ErrRevertUnknown // 早期申请未填写调出部门
+		}
+	}
+
+	var employee models.Employee
+	if err := tx.First(&employee, original.EmployeeID).Error; err != nil {
+		return nil, fmt.Errorf("员工不存在")
+	}
+
+	// 员工当前信息必须仍是原调动生效后的结果
+	var unchanged bool
+	switch original.Type {
+	case models.TransferTypeDepartment:
+		unchanged = sameDept(employee.DepartmentID, original.ToDeptID)
+	case models.TransferTypePosition:
+		unchanged = (original.ToPosition == "" || employee.Position == original.ToPosition) &&
+			(original.ToJobTitle == "" || employee.JobTitle == original.ToJobTitle)
+	default:
+		// 离退休、离职、返聘等改变员工状态的调动
+		unchanged = employee.Status == original.ToStatus
+	}
+	if !unchanged {
+		return nil, ErrRevertConflict
+	}
+	// 原调出部门已归档 (软删除) 时无法调回
+	if original.Type == models.TransferTypeDepartment && original.FromDeptID != nil {
+		var dept models.Department
+		err := tx.Unscoped().First(&dept, *original.FromDeptID).Error
+		verr := &ValidationError{}
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			verr.add("from_dept_id", "原调出部门 (#%d) 已不存在，无法撤销", *original.FromDeptID)
+		case err != nil:
+			return nil, err
+		case dept.DeletedAt.Valid:
+			verr.add("from_dept_id", "原调出部门「%s」已归档或删除，无法撤销，请先恢复该部门", dept.Name)
+		}
+		if len(verr.Errors) > 0 {
+			return nil, verr
+		}
+	}
+
+	revert := models.Transfer{
+		EmployeeID:   original.EmployeeID,
+		Type:         original.Type,
+		TransferDate: utils.FormatDate(now),
+		Reason:       fmt.Sprintf("撤销调动 #%d: %s", original.ID, reason),
+		Status:       models.TransferStatusApproved,
+		SubmitterID:  actorID,
+		ApproverID:   actorID,
+		ApprovedAt:   &now,
+		RevertOfID:   &original.ID,
+		CreatedAt:    now,
+	}
+	switch original.Type {
+	case models.TransferTypeDepartment:
+		revert.FromDeptID = employee.DepartmentID
+		revert.ToDeptID = original.FromDeptID
+	case models.TransferTypePosition:
+		revert.FromPosition, revert.ToPosition = employee.Position, original.FromPosition
+		revert.FromJobTitle, revert.ToJobTitle = employee.JobTitle, original.FromJobTitle
+	default:
+		revert.ToStatus = original.FromStatus
+	}
+	if err := tx.Create(&revert).Error; err != nil {
+		return nil, err
+	}
+	if err := audit.Created(tx, models.AuditEntityTransfer, revert.ID, revert); err != nil {
+		return nil, err
+	}
+
+	// 反向调动立即生效
+	completed, err := CompleteTransfer(tx, &revert, now)
+	if err != nil {
+		return nil, err
+	}
+	if !completed {
+		return nil, ErrStatusChanged
+	}
+
+	result := tx.Model(&models.Transfer{}).
+		Where("id = ? AND status = ? AND version = ?", original.ID, models.TransferStatusCompleted, original.Version).
+		Updates(map[string]interface{}{
+			"status":          models.TransferStatusReverted,
+			"cancelled_by_id": actorID,
+			"cancelled_at":    now,
+			"cancel_reason":   reason,
+			"version":         original.Version + 1,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrVersionConflict
+	}
+	before := *original
+	original.Status = models.TransferStatusReverted
+	original.Version++
+	original.CancelledByID = actorID
+	original.CancelledAt = &now
+	original.CancelReason = reason
+	if err := audit.Updated(tx, models.AuditEntityTransfer, original.ID, before, *original); err != nil {
+		return nil, err
+	}
+	return &revert, nil
+}
+
+// sameDept 比较两个可为空的部门ID
+func sameDept(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
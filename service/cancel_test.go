@@ -0,0 +1,47 @@
+// service/cancel_test.go
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+)
+
+// 原调出部门已归档时不能撤销部门调动，错误中应指明该部门
+func TestRevertTransferArchivedDepartment(t *testing.T) {
+	from, to := testDepartment(t), testDepartment(t)
+	employee := testEmployee(t, int(models.StatusActive), &to.ID)
+
+	testTx(t, func(tx *gorm.DB) {
+		original := &models.Transfer{EmployeeID: employee.ID, Type: models.TransferTypeDepartment, TransferDate: "2024-06-01",
+			FromDeptID: &from.ID, ToDeptID: &to.ID, Status: models.TransferStatusCompleted,
+			FromStatus: int(models.StatusActive), ToStatus: int(models.StatusActive), Version: 1}
+		if err := tx.Create(original).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Delete(&models.Department{}, from.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := RevertTransfer(tx, original, 0, "测试", time.Now())
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("期望 *ValidationError，得到 %v", err)
+		}
+		if !strings.Contains(verr.Error(), from.Name) {
+			t.Errorf("错误信息未指明部门「%s」: %v", from.Name, verr)
+		}
+
+		var current models.Employee
+		if err := tx.First(&current, employee.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if current.DepartmentID == nil || *current.DepartmentID != to.ID {
+			t.Errorf("员工部门 = %v，期望保持为 %d", current.DepartmentID, to.ID)
+		}
+	})
+}
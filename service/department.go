@@ -0,0 +1,179 @@
+// service/department.go
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DepartmentHierarchyError 上级部门设置不合法 (不存在、已删除或形成循环)
+type DepartmentHierarchyError struct {
+	Message string
+}
+
+func (e *DepartmentHierarchyError) Error() string {
+	return e.Message
+}
+
+// deptLink 部门及其上级部门
+type deptLink struct {
+	ID       uint
+	ParentID *uint
+}
+
+// loadChildren 读取部门的上下级关系：部门ID -> 直接下级部门ID。
+// 部门数量有限，一次读出全部关系在内存中遍历，各数据库通用。
+func loadChildren(db *gorm.DB, unscoped bool) (map[uint][]uint, error) {
+	query := db.Model(&models.Department{})
+	if unscoped {
+		query = query.Unscoped()
+	}
+	var links []deptLink
+	if err := query.Select("id", "parent_id").Order("id").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("查询部门层级失败: %v", err)
+	}
+	children := make(map[uint][]uint, len(links))
+	for _, l := range links {
+		if l.ParentID != nil {
+			children[*l.ParentID] = append(children[*l.ParentID], l.ID)
+		}
+	}
+	return children, nil
+}
+
+// collectSubtree 从 root 开始广度优先收集子树中的部门ID (含 root)
+func collectSubtree(children map[uint][]uint, root uint) []uint {
+	ids := []uint{root}
+	visited := map[uint]bool{root: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !visited[child] { // 防御历史数据中的循环
+				visited[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// DepartmentSubtree 返回部门及其全部下级部门的ID (不含已删除的部门)
+func DepartmentSubtree(db *gorm.DB, rootID uint) ([]uint, error) {
+	children, err := loadChildren(db, false)
+	if err != nil {
+		return nil, err
+	}
+	return collectSubtree(children, rootID), nil
+}
+
+// CheckDepartmentParent 检查 deptID 的上级部门能否设为 parentID：
+// 上级部门必须存在且未删除，且不能是部门自身或其下级部门。deptID 为 0 表示新建部门。
+// 移动部门时需在修改上级部门的事务中调用，检查期间锁定部门的层级关系直到事务结束。
+func CheckDepartmentParent(db *gorm.DB, deptID, parentID uint) error {
+	var parent models.Department
+	if err := db.Unscoped().First(&parent, parentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &DepartmentHierarchyError{Message: "上级部门不存在"}
+		}
+		return err
+	}
+	if parent.DeletedAt.Valid {
+		return &DepartmentHierarchyError{Message: fmt.Sprintf("上级部门「%s」已删除", parent.Name)}
+	}
+	if deptID == 0 {
+		return nil
+	}
+	if parentID == deptID {
+		return &DepartmentHierarchyError{Message: "不能把部门设为自己的上级部门"}
+	}
+
+	// 包含已删除的下级部门，避免恢复后出现循环。
+	// 按ID顺序锁定全部部门 (SELECT ... FOR UPDATE)，并发的移动依次检查，不会各自通过后形成循环；
+	// SQLite 不支持行锁，由事务开始时获取的写锁保证依次执行
+	children, err := loadChildren(db.Clauses(clause.Locking{Strength: "UPDATE"}), true)
+	if err != nil {
+		return err
+	}
+	for _, id := range collectSubtree(children, deptID) {
+		if id == parentID {
+			return &DepartmentHierarchyError{Message: fmt.Sprintf("不能把部门移动到自己的下级部门「%s」下", parent.Name)}
+		}
+	}
+	return nil
+}
+
+// DepartmentTree 构建部门树，rootID 不为 0 时只返回该部门及其下级部门。
+// 在岗人数按员工当前所在部门统计 (未删除，且不是离职、退休状态)。
+func DepartmentTree(db *gorm.DB, rootID uint) ([]*models.DepartmentNode, error) {
+	var depts []models.Department
+	if err := db.Order("id").Find(&depts).Error; err != nil {
+		return nil, fmt.Errorf("查询部门失败: %v", err)
+	}
+
+	var counts []struct {
+		DepartmentID uint
+		Count        int
+	}
+	if err := db.Model(&models.Employee{}).
+		Select("department_id, COUNT(*) AS count").
+		Where("department_id IS NOT NULL AND status NOT IN ?", []int{int(models.StatusResigned), int(models.StatusRetired)}).
+		Group("department_id").Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("统计部门人数失败: %v", err)
+	}
+	headcount := make(map[uint]int, len(counts))
+	for _, c := range counts {
+		headcount[c.DepartmentID] = c.Count
+	}
+
+	nodes := make(map[uint]*models.DepartmentNode, len(depts))
+	for _, d := range depts {
+		nodes[d.ID] = &models.DepartmentNode{
+			ID:        d.ID,
+			DeptNo:    d.DeptNo,
+			Name:      d.Name,
+			ManagerID: d.ManagerID,
+			ParentID:  d.ParentID,
+			Headcount: headcount[d.ID],
+			Children:  []*models.DepartmentNode{},
+		}
+	}
+
+	// 上级部门已删除 (或不存在) 的部门作为顶级部门显示
+	roots := []*models.DepartmentNode{}
+	for _, d := range depts {
+		node := nodes[d.ID]
+		if d.ParentID != nil && nodes[*d.ParentID] != nil {
+			parent := nodes[*d.ParentID]
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	var total func(node *models.DepartmentNode, depth int) int
+	total = func(node *models.DepartmentNode, depth int) int {
+		node.TotalHeadcount = node.Headcount
+		if depth > len(depts) { // 防御历史数据中的循环
+			return node.TotalHeadcount
+		}
+		for _, child := range node.Children {
+			node.TotalHeadcount += total(child, depth+1)
+		}
+		return node.TotalHeadcount
+	}
+	for _, root := range roots {
+		total(root, 0)
+	}
+
+	if rootID == 0 {
+		return roots, nil
+	}
+	root, ok := nodes[rootID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return []*models.DepartmentNode{root}, nil
+}
@@ -0,0 +1,437 @@
+// service/import.go
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// 导入文件格式
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatXLSX = "xlsx"
+)
+
+// importColumns 导入文件的表头 -> 员工字段，兼容导出的 CSV 表头和 json 字段名。
+// 导出文件中的 ID 列为数据库主键，导入时忽略，按员工编号匹配。
+var importColumns = map[string]string{
+	"员工编号": "employee_id", "employee_id": "employee_id",
+	"姓名": "name", "name": "name",
+	"状态": "status", "status": "status",
+	"部门": "department", "department": "department",
+	"职位": "position", "position": "position",
+	"职务": "job_title", "job_title": "job_title",
+	"入职日期": "arrival_date", "arrival_date": "arrival_date",
+	"电话": "phone", "phone": "phone",
+	"邮箱": "email", "email": "email",
+	"地址": "address", "address": "address",
+	"备注": "remark", "remark": "remark",
+	"id": "", "ID": "",
+}
+
+// 新增员工时必须提供的列
+var importRequired = []string{"employee_id", "name", "status", "arrival_date"}
+
+// importMaxLength 各列的最大字符数，与 models.Employee 的字段长度一致
+var importMaxLength = []struct {
+	Field string
+	Max   int
+}{
+	{"employee_id", 20},
+	{"name", 50},
+	{"position", 100},
+	{"job_title", 100},
+	{"phone", 20},
+	{"email", 100},
+}
+
+// ImportRowError 某一行的校验错误，Row 为文件中的行号 (表头为第 1 行)
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult 员工导入结果
+type ImportResult struct {
+	DryRun    bool             `json:"dry_run"`   // 仅校验，未写入数据库
+	Committed bool             `json:"committed"` // 是否已写入数据库
+	Total     int              `json:"total"`     // 数据行数 (不含空行)
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// ReadImportRows 读取 CSV 或 XLSX (第一个工作表) 的全部行
+func ReadImportRows(r io.Reader, format string) ([][]string, error) {
+	switch format {
+	case ImportFormatCSV:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		// 导出的 CSV 带 UTF-8 BOM
+		data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("CSV 格式错误: %v", err)
+		}
+		return rows, nil
+	case ImportFormatXLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("XLSX 格式错误: %v", err)
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("XLSX 文件没有工作表")
+		}
+		// 读取原始值，日期单元格为 Excel 序列号，由 parseImportDate 转换
+		rows, err := f.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, fmt.Errorf("读取工作表失败: %v", err)
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("不支持的文件格式: %s", format)
+}
+
+// ImportEmployees 按员工编号新增或更新员工，第一行为表头。
+// 已存在的员工只更新文件中出现的列；任一行校验失败时不写入任何数据。
+// dryRun 为 true 时只校验并统计。
+func ImportEmployees(db *gorm.DB, rows [][]string, dryRun bool) (*ImportResult, error) {
+	result := &ImportResult{DryRun: dryRun, Errors: []ImportRowError{}}
+	if len(rows) == 0 {
+		result.Errors = append(result.Errors, ImportRowError{Row: 1, Message: "文件为空"})
+		return result, nil
+	}
+
+	// 1. 解析表头
+	columns := make(map[string]int)
+	for i, title := range rows[0] {
+		title = strings.TrimSpace(title)
+		field, ok := importColumns[title]
+		if !ok {
+			result.Errors = append(result.Errors, ImportRowError{Row: 1, Column: title, Message: "无法识别的列"})
+			continue
+		}
+		if field == "" {
+			continue
+		}
+		if _, dup := columns[field]; dup {
+			result.Errors = append(result.Errors, ImportRowError{Row: 1, Column: title, Message: "列重复"})
+			continue
+		}
+		columns[field] = i
+	}
+	if _, ok := columns["employee_id"]; !ok {
+		result.Errors = append(result.Errors, ImportRowError{Row: 1, Column: "员工编号", Message: "缺少员工编号列"})
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	// 2. 预先加载部门和已有员工 (含已删除)
+	depts, err := loadImportDepartments(db)
+	if err != nil {
+		return nil, err
+	}
+	var codes []string
+	for _, row := range rows[1:] {
+		codes = append(codes, strings.TrimSpace(cell(row, columns["employee_id"])))
+	}
+	existing := make(map[string]models.Employee)
+	if len(codes) > 0 {
+		var employees []models.Employee
+		if err := db.Unscoped().Where("employee_id IN ?", codes).Find(&employees).Error; err != nil {
+			return nil, fmt.Errorf("查询员工失败: %v", err)
+		}
+		for _, emp := range employees {
+			// 驱动读出的日期可能带时间部分，统一后再比较
+			emp.ArrivalDate = utils.DateOnly(emp.ArrivalDate)
+			existing[emp.EmployeeID] = emp
+		}
+	}
+
+	// 3. 逐行校验
+	type pending struct {
+		before *models.Employee // nil 表示新增
+		after  models.Employee
+	}
+	var changes []pending
+	seen := make(map[string]int)
+	for i, row := range rows[1:] {
+		line := i + 2
+		if blankRow(row) {
+			continue
+		}
+		result.Total++
+
+		rowErr := func(field, message string) {
+			result.Errors = append(result.Errors, ImportRowError{Row: line, Column: columnTitle(field), Message: message})
+		}
+		value := func(field string) (string, bool) {
+			idx, ok := columns[field]
+			if !ok {
+				return "", false
+			}
+			return strings.TrimSpace(cell(row, idx)), true
+		}
+
+		code, _ := value("employee_id")
+		if code == "" {
+			rowErr("employee_id", "员工编号不能为空")
+			continue
+		}
+		if first, dup := seen[code]; dup {
+			rowErr("employee_id", fmt.Sprintf("员工编号与第 %d 行重复", first))
+			continue
+		}
+		seen[code] = line
+
+		errCount := len(result.Errors)
+		for _, limit := range importMaxLength {
+			if v, ok := value(limit.Field); ok && utf8.RuneCountInString(v) > limit.Max {
+				rowErr(limit.Field, fmt.Sprintf("%s不能超过 %d 个字符", columnTitle(limit.Field), limit.Max))
+			}
+		}
+		if len(result.Errors) > errCount {
+			continue
+		}
+
+		var emp models.Employee
+		var before *models.Employee
+		if current, ok := existing[code]; ok {
+			if current.DeletedAt.Valid {
+				rowErr("employee_id", "员工编号已被已删除的员工使用，请先恢复该员工")
+				continue
+			}
+			copied := current
+			before = &copied
+			emp = current
+		} else {
+			emp.EmployeeID = code
+			for _, field := range importRequired {
+				if v, ok := value(field); !ok || v == "" {
+					rowErr(field, columnTitle(field)+"不能为空")
+				}
+			}
+		}
+
+		if v, ok := value("name"); ok && v != "" {
+			emp.Name = v
+		}
+		if v, ok := value("status"); ok && v != "" {
+			status, ok := parseImportStatus(v)
+			switch {
+			case !ok:
+				rowErr("status", "无效的状态: "+v)
+			case before != nil:
+				if err := CheckStatusChange(before.Status, status); err != nil {
+					rowErr("status", err.Error())
+				}
+			}
+			emp.Status = status
+		}
+		if v, ok := value("arrival_date"); ok && v != "" {
+			date, err := parseImportDate(v)
+			if err != nil {
+				rowErr("arrival_date", "入职日期格式错误: "+v)
+			}
+			emp.ArrivalDate = date
+		}
+		if v, ok := value("department"); ok {
+			if v == "" {
+				emp.DepartmentID = nil
+			} else if id, err := depts.resolve(v); err != nil {
+				rowErr("department", err.Error())
+			} else {
+				emp.DepartmentID = &id
+			}
+		}
+		for field, target := range map[string]*string{
+			"position":  &emp.Position,
+			"job_title": &emp.JobTitle,
+			"phone":     &emp.Phone,
+			"email":     &emp.Email,
+			"address":   &emp.Address,
+			"remark":    &emp.Remark,
+		} {
+			if v, ok := value(field); ok {
+				*target = v
+			}
+		}
+		if len(result.Errors) > errCount {
+			continue
+		}
+
+		switch {
+		case before == nil:
+			result.Created++
+		case len(audit.Diff(before, emp)) == 0:
+			result.Unchanged++
+			continue
+		default:
+			result.Updated++
+		}
+		changes = append(changes, pending{before: before, after: emp})
+	}
+
+	if len(result.Errors) > 0 || dryRun {
+		return result, nil
+	}
+
+	// 4. 全部写入或全部回滚
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for i := range changes {
+			change := &changes[i]
+			if change.before == nil {
+				if err := tx.Create(&change.after).Error; err != nil {
+					return fmt.Errorf("新增员工 %s 失败: %v", change.after.EmployeeID, err)
+				}
+				if err := audit.Created(tx, models.AuditEntityEmployee, change.after.ID, change.after); err != nil {
+					return err
+				}
+				continue
+			}
+			// 只更新发生变化的字段
+			diff := audit.Diff(change.before, change.after)
+			updates := make(map[string]interface{}, len(diff))
+			for field, d := range diff {
+				updates[field] = d.New
+			}
+			// 按校验时读取的版本号更新，期间被其他人修改的员工整批回滚
+			id := change.before.ID
+			if err := UpdateVersioned(tx, &models.Employee{}, id, change.before.Version, updates); err != nil {
+				return fmt.Errorf("更新员工 %s 失败: %w", change.after.EmployeeID, err)
+			}
+			if err := audit.Record(tx, models.AuditEntityEmployee, id, models.AuditActionUpdate, diff); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Committed = true
+	return result, nil
+}
+
+// importDepartments 按部门编号或名称查找部门
+type importDepartments struct {
+	byNo   map[string]uint
+	byName map[string][]uint
+}
+
+func loadImportDepartments(db *gorm.DB) (*importDepartments, error) {
+	var depts []models.Department
+	if err := db.Find(&depts).Error; err != nil {
+		return nil, fmt.Errorf("查询部门失败: %v", err)
+	}
+	d := &importDepartments{byNo: map[string]uint{}, byName: map[string][]uint{}}
+	for _, dept := range depts {
+		d.byNo[dept.DeptNo] = dept.ID
+		d.byName[dept.Name] = append(d.byName[dept.Name], dept.ID)
+	}
+	return d, nil
+}
+
+// resolve 先按部门编号匹配，再按名称匹配 (导出文件中为部门名称)
+func (d *importDepartments) resolve(v string) (uint, error) {
+	if id, ok := d.byNo[v]; ok {
+		return id, nil
+	}
+	switch ids := d.byName[v]; len(ids) {
+	case 0:
+		return 0, fmt.Errorf("部门不存在: %s", v)
+	case 1:
+		return ids[0], nil
+	}
+	return 0, fmt.Errorf("存在多个名为 %s 的部门，请改用部门编号", v)
+}
+
+// parseImportStatus 状态可以是中文名称 (导出格式) 或数字 1-6
+func parseImportStatus(v string) (int, bool) {
+	if n, err := strconv.Atoi(v); err == nil {
+		return n, n >= int(models.StatusActive) && n <= int(models.StatusRetired)
+	}
+	for s := int(models.StatusActive); s <= int(models.StatusRetired); s++ {
+		if models.GetStatusText(s) == v {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// importDateLayouts 导入时接受的日期格式
+var importDateLayouts = []string{utils.DateLayout, "2006/01/02", "2006/1/2", "2006-1-2", "2006.01.02"}
+
+// maxExcelSerial Excel 日期序列号的上限 (9999-12-31)
+const maxExcelSerial = 2958465
+
+// parseImportDate 解析日期，XLSX 中的日期单元格为 Excel 序列号。
+// 只有 1 到 9999-12-31 范围内的数字按序列号处理，20240301 等其他数字视为格式错误
+func parseImportDate(v string) (string, error) {
+	if serial, err := strconv.ParseFloat(v, 64); err == nil {
+		if serial < 1 || serial > maxExcelSerial {
+			return "", fmt.Errorf("日期格式错误: %s", v)
+		}
+		t, err := excelize.ExcelDateToTime(serial, false)
+		if err != nil {
+			return "", err
+		}
+		return utils.FormatDate(t), nil
+	}
+	if t, err := utils.ParseDate(v); err == nil {
+		return utils.FormatDate(t), nil
+	}
+	for _, layout := range importDateLayouts {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return utils.FormatDate(t), nil
+		}
+	}
+	return "", fmt.Errorf("日期格式错误: %s", v)
+}
+
+// columnTitle 字段对应的中文列名，用于错误提示
+func columnTitle(field string) string {
+	for title, f := range importColumns {
+		if f == field && title != field {
+			return title
+		}
+	}
+	return field
+}
+
+func cell(row []string, idx int) string {
+	if idx < len(row) {
+		return row[idx]
+	}
+	return ""
+}
+
+func blankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+// service/import_test.go
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestReadImportRowsCSV(t *testing.T) {
+	// 导出的 CSV 带 UTF-8 BOM，读入后表头不应带 BOM
+	data := "\xEF\xBB\xBF员工编号,姓名,状态\nE001,张三,在职\nE002,\"李,四\",试用\n"
+	rows, err := ReadImportRows(strings.NewReader(data), ImportFormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"员工编号", "姓名", "状态"}, {"E001", "张三", "在职"}, {"E002", "李,四", "试用"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q，期望 %q", rows, want)
+	}
+
+	if _, err := ReadImportRows(strings.NewReader("a,\"b\nc"), ImportFormatCSV); err == nil {
+		t.Error("引号未闭合的 CSV 应返回错误")
+	}
+}
+
+func TestReadImportRowsXLSX(t *testing.T) {
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	for i, row := range [][]interface{}{
+		{"员工编号", "姓名", "入职日期"},
+		{"E001", "张三", 45292}, // Excel 日期序列号 2024-01-01
+	} {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := ReadImportRows(&buf, ImportFormatXLSX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"员工编号", "姓名", "入职日期"}, {"E001", "张三", "45292"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q，期望 %q", rows, want)
+	}
+	if date, err := parseImportDate(rows[1][2]); err != nil || date != "2024-01-01" {
+		t.Errorf("入职日期 = %q, %v，期望 2024-01-01", date, err)
+	}
+
+	// 超出 Excel 序列号范围的数字不能当作日期
+	for _, v := range []string{"20240301", "0", "-1"} {
+		if date, err := parseImportDate(v); err == nil {
+			t.Errorf("parseImportDate(%q) = %q，期望日期格式错误", v, date)
+		}
+	}
+
+	if _, err := ReadImportRows(strings.NewReader("not a zip"), ImportFormatXLSX); err == nil {
+		t.Error("无效的 XLSX 应返回错误")
+	}
+}
+
+// dry run 只校验并统计，不写入数据库；出错的行带行号和列名
+func TestImportEmployeesDryRun(t *testing.T) {
+	rows := [][]string{
+		{"员工编号", "姓名", "状态", "入职日期"},
+		{"DRY001", "张三", "在职", "2024-01-01"},
+		{"DRY002", "", "在职", "2024-01-01"},
+		{"DRY003", "李四", "未知", "2024-01-01"},
+		{"DRY004", "王五", "在职", "2024-13-01"},
+	}
+	db := database.GetDB()
+	result, err := ImportEmployees(db, rows, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Committed || result.Total != 4 {
+		t.Errorf("committed = %v, total = %d，期望 false, 4", result.Committed, result.Total)
+	}
+	var got []string
+	for _, e := range result.Errors {
+		got = append(got, fmt.Sprintf("%d:%s", e.Row, e.Column))
+	}
+	want := []string{"3:姓名", "4:状态", "5:入职日期"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors = %+v，期望 %v", result.Errors, want)
+	}
+
+	var count int64
+	if err := db.Table("employees").Where("employee_id LIKE ?", "DRY%").Count(&count).Error; err != nil || count != 0 {
+		t.Errorf("dry run 写入了 %d 名员工 (%v)", count, err)
+	}
+}
+
+// 超长的列按行报告错误，dry run 不写入数据
+func TestImportEmployeesMaxLength(t *testing.T) {
+	rows := [][]string{
+		{"员工编号", "姓名", "状态", "入职日期", "电话"},
+		{"IMP001", strings.Repeat("名", 51), "在职", "2024-01-01", "123"},
+		{"IMP002", "王五", "在职", "2024-01-01", strings.Repeat("1", 21)},
+		{"IMP003", "赵六", "在职", "2024-01-01", ""},
+	}
+	result, err := ImportEmployees(database.GetDB(), rows, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range result.Errors {
+		got = append(got, e.Message)
+	}
+	want := []string{"姓名不能超过 50 个字符", "电话不能超过 20 个字符"}
+	if len(result.Errors) != 2 || result.Errors[0].Row != 2 || result.Errors[1].Row != 3 ||
+		!strings.Contains(got[0], want[0]) || !strings.Contains(got[1], want[1]) {
+		t.Errorf("errors = %+v，期望第 2、3 行分别报告 %q", result.Errors, want)
+	}
+	if result.Committed {
+		t.Error("dry run 不应写入数据库")
+	}
+}
@@ -0,0 +1,84 @@
+// service/main_test.go
+package service
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+)
+
+// 测试使用临时目录中的 SQLite 数据库，执行全部迁移后供本包的测试共用
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "hrms-service-test-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	log.SetOutput(io.Discard)
+	cfg := database.GetDefaultConfig()
+	cfg.Driver = database.DriverSQLite
+	cfg.Path = filepath.Join(dir, "test.db")
+	if _, err := database.Init(cfg); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := database.MigrateUp(); err != nil {
+		log.Fatal(err)
+	}
+	log.SetOutput(os.Stderr)
+	return m.Run()
+}
+
+var testSeq atomic.Int64
+
+// testEmployee 新建一名员工，编号自动生成，各测试之间互不影响
+func testEmployee(t *testing.T, status int, deptID *uint) *models.Employee {
+	t.Helper()
+	employee := &models.Employee{
+		EmployeeID:   fmt.Sprintf("T%05d", testSeq.Add(1)),
+		Name:         "测试员工",
+		Status:       status,
+		ArrivalDate:  "2020-03-01",
+		Position:     "工程师",
+		JobTitle:     "组员",
+		DepartmentID: deptID,
+	}
+	if err := database.GetDB().Create(employee).Error; err != nil {
+		t.Fatalf("创建员工失败: %v", err)
+	}
+	return employee
+}
+
+// testDepartment 新建一个部门
+func testDepartment(t *testing.T) *models.Department {
+	t.Helper()
+	n := testSeq.Add(1)
+	dept := &models.Department{DeptNo: fmt.Sprintf("D%05d", n), Name: fmt.Sprintf("测试部门%d", n)}
+	if err := database.GetDB().Create(dept).Error; err != nil {
+		t.Fatalf("创建部门失败: %v", err)
+	}
+	return dept
+}
+
+// testTx 在事务中执行 fn，结束后回滚
+func testTx(t *testing.T, fn func(tx *gorm.DB)) {
+	t.Helper()
+	tx := database.GetDB().Begin()
+	if tx.Error != nil {
+		t.Fatalf("开始事务失败: %v", tx.Error)
+	}
+	defer tx.Rollback()
+	fn(tx)
+}
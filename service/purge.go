@@ -0,0 +1,194 @@
+// service/purge.go
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+)
+
+// PurgeResult 清除已删除记录的结果
+type PurgeResult struct {
+	Before      time.Time `json:"before"`      // 删除时间早于该时刻的记录才会被清除
+	DryRun      bool      `json:"dry_run"`     // 仅预览，未实际清除
+	Employees   []uint    `json:"employees"`   // 清除的员工ID
+	Departments []uint    `json:"departments"` // 清除的部门ID
+	Transfers   int       `json:"transfers"`   // 随员工一并清除的调动记录数
+	Skipped     []string  `json:"skipped"`     // 仍被引用而保留的记录
+}
+
+// PurgeDeleted 彻底清除删除时间早于 before 的员工和部门。
+// 员工的调动记录及审批步骤一并清除，每条调动记录清除前的内容写入审计日志；仍被其他记录引用的
+// (部门主管、用户账号、员工所在部门、调动记录) 跳过并在结果中说明。
+// dryRun 为 true 时只统计不删除。
+func PurgeDeleted(db *gorm.DB, before time.Time, dryRun bool) (*PurgeResult, error) {
+	result := &PurgeResult{Before: before, DryRun: dryRun, Employees: []uint{}, Departments: []uint{}, Skipped: []string{}}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var employees []models.Employee
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Order("id").Find(&employees).Error; err != nil {
+			return fmt.Errorf("查询已删除员工失败: %v", err)
+		}
+		for i := range employees {
+			emp := &employees[i]
+			if reason, err := employeeReference(tx, emp.ID); err != nil {
+				return err
+			} else if reason != "" {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("员工 %s (#%d) %s", emp.EmployeeID, emp.ID, reason))
+				continue
+			}
+
+			var transfers []models.Transfer
+			if err := tx.Where("employee_id = ?", emp.ID).Order("id").Find(&transfers).Error; err != nil {
+				return err
+			}
+			result.Employees = append(result.Employees, emp.ID)
+			result.Transfers += len(transfers)
+			if dryRun {
+				continue
+			}
+
+			if len(transfers) > 0 {
+				transferIDs := make([]uint, len(transfers))
+				for i := range transfers {
+					transferIDs[i] = transfers[i].ID
+				}
+				if err := tx.Where("transfer_id IN ?", transferIDs).Delete(&models.TransferApprovalStep{}).Error; err != nil {
+					return err
+				}
+				if err := tx.Where("id IN ?", transferIDs).Delete(&models.Transfer{}).Error; err != nil {
+					return err
+				}
+				for i := range transfers {
+					if err := audit.Record(tx, models.AuditEntityTransfer, transfers[i].ID, models.AuditActionPurge, audit.Diff(&transfers[i], nil)); err != nil {
+						return err
+					}
+				}
+			}
+			if err := tx.Unscoped().Delete(&models.Employee{}, emp.ID).Error; err != nil {
+				return err
+			}
+			if err := audit.Record(tx, models.AuditEntityEmployee, emp.ID, models.AuditActionPurge, audit.Diff(emp, nil)); err != nil {
+				return err
+			}
+		}
+
+		var depts []models.Department
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Order("id").Find(&depts).Error; err != nil {
+			return fmt.Errorf("查询已删除部门失败: %v", err)
+		}
+		// 下级部门清除后上级部门才不再被引用，逐轮处理直到没有可清除的部门
+		done := map[uint]bool{}
+		skipped := map[uint]string{}
+		for progress := true; progress; {
+			progress = false
+			for i := range depts {
+				dept := &depts[i]
+				if done[dept.ID] {
+					continue
+				}
+				reason, err := departmentReference(tx, dept.ID, result.Employees, result.Departments)
+				if err != nil {
+					return err
+				}
+				if reason != "" {
+					skipped[dept.ID] = fmt.Sprintf("部门 %s (#%d) %s", dept.DeptNo, dept.ID, reason)
+					continue
+				}
+				delete(skipped, dept.ID)
+				progress = true
+				result.Departments = append(result.Departments, dept.ID)
+				if !dryRun {
+					if err := tx.Unscoped().Delete(&models.Department{}, dept.ID).Error; err != nil {
+						return err
+					}
+					if err := audit.Record(tx, models.AuditEntityDepartment, dept.ID, models.AuditActionPurge, audit.Diff(dept, nil)); err != nil {
+						return err
+					}
+				}
+				done[dept.ID] = true
+			}
+		}
+		for i := range depts {
+			if reason, ok := skipped[depts[i].ID]; ok {
+				result.Skipped = append(result.Skipped, reason)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// employeeReference 员工仍被引用时返回原因
+func employeeReference(tx *gorm.DB, employeeID uint) (string, error) {
+	var count int64
+	if err := tx.Model(&models.Department{}).Unscoped().Where("manager_id = ?", employeeID).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "仍是部门主管", nil
+	}
+	if err := tx.Model(&models.User{}).Where("employee_id = ?", employeeID).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "仍关联用户账号", nil
+	}
+	return "", nil
+}
+
+// departmentReference 部门仍被引用时返回原因。
+// purged 为本次清除的员工，其本人及调动记录不算引用；purgedDepts 为本次清除的部门，
+// 不算作下级部门 (预览时尚未实际删除)。
+func departmentReference(tx *gorm.DB, deptID uint, purged, purgedDepts []uint) (string, error) {
+	excluding := func(query *gorm.DB, column string) *gorm.DB {
+		if len(purged) == 0 {
+			return query
+		}
+		return query.Where(column+" NOT IN ?", purged)
+	}
+
+	var count int64
+	if err := excluding(tx.Model(&models.Employee{}).Unscoped().Where("department_id = ?", deptID), "id").Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "仍有员工 (含已删除员工) 属于该部门", nil
+	}
+	transfers := tx.Model(&models.Transfer{}).Where("(from_dept_id = ? OR to_dept_id = ?)", deptID, deptID)
+	if err := excluding(transfers, "employee_id").Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "仍被调动记录引用", nil
+	}
+	steps := tx.Model(&models.TransferApprovalStep{}).Where("dept_id = ?", deptID)
+	if len(purged) > 0 {
+		steps = steps.Where("transfer_id NOT IN (?)", tx.Model(&models.Transfer{}).Select("id").Where("employee_id IN ?", purged))
+	}
+	if err := steps.Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "仍被审批记录引用", nil
+	}
+	children := tx.Model(&models.Department{}).Unscoped().Where("parent_id = ?", deptID)
+	if len(purgedDepts) > 0 {
+		children = children.Where("id NOT IN ?", purgedDepts)
+	}
+	if err := children.Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "仍有下级部门 (含已删除部门)", nil
+	}
+	return "", nil
+}
@@ -0,0 +1,46 @@
+// service/purge_test.go
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+)
+
+// 清除员工时一并清除的调动记录应写入审计日志
+func TestPurgeDeletedAuditsTransfers(t *testing.T) {
+	from, to := testDepartment(t), testDepartment(t)
+	employee := testEmployee(t, int(models.StatusActive), &from.ID)
+
+	testTx(t, func(tx *gorm.DB) {
+		transfer := &models.Transfer{EmployeeID: employee.ID, Type: models.TransferTypeDepartment, TransferDate: "2024-06-01",
+			FromDeptID: &from.ID, ToDeptID: &to.ID, Status: models.TransferStatusCompleted, Version: 1}
+		if err := tx.Create(transfer).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Delete(&models.Employee{}, employee.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := PurgeDeleted(tx, time.Now().Add(time.Minute), false)
+		if err != nil {
+			t.Fatalf("清除失败: %v", err)
+		}
+		if result.Transfers != 1 {
+			t.Errorf("清除的调动记录数 = %d，期望 1", result.Transfers)
+		}
+
+		var logs []models.AuditLog
+		if err := tx.Where("entity = ? AND entity_id = ?", models.AuditEntityTransfer, transfer.ID).Find(&logs).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(logs) != 1 || logs[0].Action != models.AuditActionPurge {
+			t.Fatalf("调动的审计日志 = %+v，期望一条清除记录", logs)
+		}
+		if change, ok := logs[0].Changes["to_dept_id"]; !ok || change.New != nil {
+			t.Errorf("审计日志未记录清除前的调入部门: %+v", logs[0].Changes)
+		}
+	})
+}
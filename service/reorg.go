@@ -0,0 +1,397 @@
+// service/reorg.go
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
+	"gorm.io/gorm"
+)
+
+// ReorgError 部门合并/拆分请求不合法
+type ReorgError struct {
+	Message string
+}
+
+func (e *ReorgError) Error() string {
+	return e.Message
+}
+
+func reorgErrorf(format string, args ...interface{}) error {
+	return &ReorgError{Message: fmt.Sprintf(format, args...)}
+}
+
+// ReorgSkipped 合并/拆分时未调动的员工
+type ReorgSkipped struct {
+	EmployeeID uint   `json:"employee_id"`
+	Name       string `json:"name"`
+	Reason     string `json:"reason"`
+}
+
+// ReorgResult 部门合并/拆分结果
+type ReorgResult struct {
+	Source    models.Department   `json:"source"`    // 原部门
+	Targets   []models.Department `json:"targets"`   // 员工转入的部门
+	Transfers []uint              `json:"transfers"` // 生成并已生效的部门调动ID
+	Skipped   []ReorgSkipped      `json:"skipped"`   // 未调动的员工 (离职、退休员工；拆分时留在原部门的记录中)
+	Moved     []uint              `json:"moved"`     // 合并时未办理调动、直接转入目标部门的员工ID (离职、退休及已删除的员工)
+	Children  []uint              `json:"children"`  // 改挂到新上级部门的下级部门ID
+	Archived  bool                `json:"archived"`  // 原部门是否已归档 (软删除)
+}
+
+// MergeDepartment 把 sourceID 部门合并到 targetID 部门，需在事务中调用：
+// 原部门的员工逐一生成立即生效的部门调动转入目标部门，离职、退休及已删除的员工不办理调动，
+// 直接改为目标部门；目标部门没有主管时由原部门主管担任，原部门的下级部门改挂到目标部门下，最后归档原部门。
+func MergeDepartment(tx *gorm.DB, sourceID, targetID, actorID uint, reason string, now time.Time) (*ReorgResult, error) {
+	if sourceID == targetID {
+		return nil, reorgErrorf("不能把部门合并到自身")
+	}
+	source, employees, err := loadReorgSource(tx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	var target models.Department
+	if err := tx.First(&target, targetID).Error; err != nil {
+		return nil, reorgErrorf("目标部门不存在")
+	}
+	children, err := loadChildren(tx, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range collectSubtree(children, source.ID) {
+		if id == target.ID {
+			return nil, reorgErrorf("不能把部门合并到自己的下级部门「%s」", target.Name)
+		}
+	}
+
+	result := &ReorgResult{Source: *source, Transfers: []uint{}, Skipped: []ReorgSkipped{}, Moved: []uint{}, Children: []uint{}}
+	reason = reorgReason(fmt.Sprintf("部门合并: %s 并入 %s", source.Name, target.Name), reason)
+	if result.Transfers, result.Skipped, err = reorgTransfers(tx, employees, source, &target, actorID, reason, now); err != nil {
+		return nil, err
+	}
+	if result.Moved, err = moveRemaining(tx, source, &target); err != nil {
+		return nil, err
+	}
+	if target.ManagerID == 0 && source.ManagerID != 0 {
+		if err := setManager(tx, &target, source.ManagerID); err != nil {
+			return nil, err
+		}
+	}
+	result.Targets = []models.Department{target}
+
+	if result.Children, err = archiveDepartment(tx, source, &target.ID); err != nil {
+		return nil, err
+	}
+	result.Archived = true
+	return result, tx.Unscoped().First(&result.Source, source.ID).Error
+}
+
+// SplitDepartment 按员工名单把 sourceID 部门拆分到若干部门，需在事务中调用：
+// 每一部分转入已有部门或新建部门 (与原部门同一上级)，员工逐一生成立即生效的部门调动；
+// 原部门主管随其所在部分转入，该部门没有主管时由其担任，不归档时原部门的主管随之清空。
+// 离职、退休的员工不办理调动，在结果中列出。
+// req.Archive 为 true 时要求原部门的在职员工全部分配，下级部门改挂到原部门的上级下，并归档原部门。
+func SplitDepartment(tx *gorm.DB, sourceID uint, req models.SplitDepartmentRequest, actorID uint, now time.Time) (*ReorgResult, error) {
+	source, employees, err := loadReorgSource(tx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[uint]models.Employee, len(employees))
+	for _, emp := range employees {
+		members[emp.ID] = emp
+	}
+
+	// 先校验全部名单，再执行调动
+	active := 0
+	for _, emp := range employees {
+		if !IsInactiveStatus(emp.Status) {
+			active++
+		}
+	}
+	assigned := make(map[uint]bool, len(employees))
+	assignedActive := 0
+	for i, part := range req.Parts {
+		if part.DeptID == 0 && (part.DeptNo == "" || part.Name == "") {
+			return nil, reorgErrorf("第 %d 部分需指定已有部门 (dept_id) 或新部门的编号和名称", i+1)
+		}
+		if part.DeptID == source.ID {
+			return nil, reorgErrorf("第 %d 部分不能转入原部门", i+1)
+		}
+		for _, id := range part.EmployeeIDs {
+			if _, ok := members[id]; !ok {
+				return nil, reorgErrorf("第 %d 部分的员工 #%d 不属于部门「%s」", i+1, id, source.Name)
+			}
+			if assigned[id] {
+				return nil, reorgErrorf("员工 #%d 在多个部分中重复出现", id)
+			}
+			assigned[id] = true
+			if !IsInactiveStatus(members[id].Status) {
+				assignedActive++
+			}
+		}
+	}
+	if req.Archive && assignedActive < active {
+		return nil, reorgErrorf("归档原部门需要分配其全部 %d 名在职员工，尚有 %d 名未分配", active, active-assignedActive)
+	}
+
+	result := &ReorgResult{Source: *source, Targets: []models.Department{}, Transfers: []uint{}, Skipped: []ReorgSkipped{}, Moved: []uint{}, Children: []uint{}}
+	moved := make(map[uint]bool, len(assigned))
+	for i, part := range req.Parts {
+		target, err := splitTarget(tx, source, part, i+1)
+		if err != nil {
+			return nil, err
+		}
+
+		partEmployees := make([]models.Employee, 0, len(part.EmployeeIDs))
+		for _, id := range part.EmployeeIDs {
+			partEmployees = append(partEmployees, members[id])
+		}
+		partReason := reorgReason(fmt.Sprintf("部门拆分: %s 拆分至 %s", source.Name, target.Name), req.Reason)
+		ids, skipped, err := reorgTransfers(tx, partEmployees, source, target, actorID, partReason, now)
+		if err != nil {
+			return nil, err
+		}
+		result.Transfers = append(result.Transfers, ids...)
+		result.Skipped = append(result.Skipped, skipped...)
+		for _, id := range part.EmployeeIDs {
+			moved[id] = !IsInactiveStatus(members[id].Status)
+		}
+
+		manager := part.ManagerID
+		if manager == 0 && target.ManagerID == 0 && source.ManagerID != 0 && moved[source.ManagerID] && assignedTo(part, source.ManagerID) {
+			manager = source.ManagerID
+		}
+		if manager != 0 && manager != target.ManagerID {
+			if err := setManager(tx, target, manager); err != nil {
+				return nil, err
+			}
+		}
+		result.Targets = append(result.Targets, *target)
+	}
+
+	if req.Archive {
+		if result.Children, err = archiveDepartment(tx, source, source.ParentID); err != nil {
+			return nil, err
+		}
+		result.Archived = true
+		return result, tx.Unscoped().First(&result.Source, source.ID).Error
+	}
+
+	// 原部门主管已转出，不再担任原部门主管
+	if source.ManagerID != 0 && moved[source.ManagerID] {
+		if err := setManager(tx, source, 0); err != nil {
+			return nil, err
+		}
+		result.Source = *source
+	}
+	return result, nil
+}
+
+// loadReorgSource 读取待合并/拆分的部门及其员工。
+// 部门或其员工存在未生效的部门调动时拒绝，避免调动生效时引用已归档的部门或覆盖本次结果。
+func loadReorgSource(tx *gorm.DB, deptID uint) (*models.Department, []models.Employee, error) {
+	var source models.Department
+	if err := tx.First(&source, deptID).Error; err != nil {
+		return nil, nil, reorgErrorf("部门不存在")
+	}
+
+	open := []int{models.TransferStatusPending, models.TransferStatusApproved}
+	var count int64
+	if err := tx.Model(&models.Transfer{}).
+		Where("(from_dept_id = ? OR to_dept_id = ?) AND status IN ?", source.ID, source.ID, open).
+		Count(&count).Error; err != nil {
+		return nil, nil, err
+	}
+	if count > 0 {
+		return nil, nil, reorgErrorf("部门「%s」存在未生效的调动申请，请先处理", source.Name)
+	}
+
+	var employees []models.Employee
+	if err := tx.Where("department_id = ?", source.ID).Order("id").Find(&employees).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(employees) > 0 {
+		ids := make([]uint, len(employees))
+		for i, emp := range employees {
+			ids[i] = emp.ID
+		}
+		if err := tx.Model(&models.Transfer{}).
+			Where("employee_id IN ? AND type = ? AND status IN ?", ids, models.TransferTypeDepartment, open).
+			Count(&count).Error; err != nil {
+			return nil, nil, err
+		}
+		if count > 0 {
+			return nil, nil, reorgErrorf("部门「%s」有 %d 条员工部门调动申请尚未生效，请先处理", source.Name, count)
+		}
+	}
+	return &source, employees, nil
+}
+
+// splitTarget 拆分的一部分转入的部门：已有部门或新建部门
+func splitTarget(tx *gorm.DB, source *models.Department, part models.SplitPart, index int) (*models.Department, error) {
+	var target models.Department
+	if part.DeptID != 0 {
+		if err := tx.First(&target, part.DeptID).Error; err != nil {
+			return nil, reorgErrorf("第 %d 部分的部门不存在", index)
+		}
+		return &target, nil
+	}
+
+	// 已删除的部门仍占用部门编号
+	var existing models.Department
+	if err := tx.Unscoped().Where("dept_no = ?", part.DeptNo).First(&existing).Error; err == nil {
+		return nil, reorgErrorf("第 %d 部分的部门编号 %s 已存在", index, part.DeptNo)
+	}
+	target = models.Department{
+		DeptNo:    part.DeptNo,
+		Name:      part.Name,
+		ManagerID: part.ManagerID,
+		ParentID:  source.ParentID,
+	}
+	if err := tx.Create(&target).Error; err != nil {
+		return nil, err
+	}
+	if err := audit.Created(tx, models.AuditEntityDepartment, target.ID, target); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// reorgTransfers 为每名员工生成一条已批准的部门调动并立即生效，返回调动ID及未调动的员工。
+// 离职、退休的员工跳过；其他员工按单条提交调动的规则校验，不通过时整体失败。
+func reorgTransfers(tx *gorm.DB, employees []models.Employee, from, to *models.Department, actorID uint, reason string, now time.Time) ([]uint, []ReorgSkipped, error) {
+	ids := make([]uint, 0, len(employees))
+	skipped := []ReorgSkipped{}
+	for _, emp := range employees {
+		if IsInactiveStatus(emp.Status) {
+			skipped = append(skipped, ReorgSkipped{
+				EmployeeID: emp.ID,
+				Name:       emp.Name,
+				Reason:     fmt.Sprintf("员工已%s，不办理部门调动", models.GetStatusText(emp.Status)),
+			})
+			continue
+		}
+
+		fromID, toID := from.ID, to.ID
+		transfer := models.Transfer{
+			EmployeeID:   emp.ID,
+			Type:         models.TransferTypeDepartment,
+			TransferDate: utils.FormatDate(now),
+			FromDeptID:   &fromID,
+			ToDeptID:     &toID,
+			Reason:       reason,
+			Status:       models.TransferStatusApproved,
+			SubmitterID:  actorID,
+			ApproverID:   actorID,
+			ApprovedAt:   &now,
+			CreatedAt:    now,
+		}
+		employee := emp
+		if err := ValidateTransfer(tx, &employee, &transfer); err != nil {
+			return nil, nil, reorgErrorf("员工 %s 不能调入「%s」: %v", emp.EmployeeID, to.Name, err)
+		}
+		if err := tx.Create(&transfer).Error; err != nil {
+			return nil, nil, err
+		}
+		if err := audit.Created(tx, models.AuditEntityTransfer, transfer.ID, transfer); err != nil {
+			return nil, nil, err
+		}
+		completed, err := CompleteTransfer(tx, &transfer, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("员工 %s 的部门调动生效失败: %w", emp.EmployeeID, err)
+		}
+		if !completed {
+			return nil, nil, ErrStatusChanged
+		}
+		ids = append(ids, transfer.ID)
+	}
+	return ids, skipped, nil
+}
+
+// moveRemaining 把调动后仍属于原部门的员工 (离职、退休及已删除的) 直接改为目标部门，
+// 避免其档案引用已归档的部门
+func moveRemaining(tx *gorm.DB, source, target *models.Department) ([]uint, error) {
+	var employees []models.Employee
+	if err := tx.Unscoped().Where("department_id = ?", source.ID).Order("id").Find(&employees).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(employees))
+	for _, emp := range employees {
+		before := emp
+		if err := UpdateVersioned(tx.Unscoped(), &models.Employee{}, emp.ID, emp.Version, map[string]interface{}{"department_id": target.ID}); err != nil {
+			return nil, err
+		}
+		targetID := target.ID
+		emp.DepartmentID = &targetID
+		emp.Version++
+		if err := audit.Updated(tx, models.AuditEntityEmployee, emp.ID, before, emp); err != nil {
+			return nil, err
+		}
+		ids = append(ids, emp.ID)
+	}
+	return ids, nil
+}
+
+// setManager 更新部门主管
+func setManager(tx *gorm.DB, dept *models.Department, managerID uint) error {
+	before := *dept
+	if err := UpdateVersioned(tx, &models.Department{}, dept.ID, dept.Version, map[string]interface{}{"manager_id": managerID}); err != nil {
+		return err
+	}
+	dept.ManagerID = managerID
+	dept.Version++
+	return audit.Updated(tx, models.AuditEntityDepartment, dept.ID, before, *dept)
+}
+
+// archiveDepartment 把部门的下级部门 (含已删除的) 改挂到 parentID 下，清空主管后软删除该部门
+func archiveDepartment(tx *gorm.DB, dept *models.Department, parentID *uint) ([]uint, error) {
+	var children []models.Department
+	if err := tx.Unscoped().Where("parent_id = ?", dept.ID).Order("id").Find(&children).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(children))
+	for _, child := range children {
+		before := child
+		if err := UpdateVersioned(tx.Unscoped(), &models.Department{}, child.ID, child.Version, map[string]interface{}{"parent_id": parentID}); err != nil {
+			return nil, err
+		}
+		child.ParentID = parentID
+		child.Version++
+		if err := audit.Updated(tx, models.AuditEntityDepartment, child.ID, before, child); err != nil {
+			return nil, err
+		}
+		ids = append(ids, child.ID)
+	}
+
+	// 归档的部门不再有主管，其主管可以被清除或担任其他部门主管
+	if dept.ManagerID != 0 {
+		if err := setManager(tx, dept, 0); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Delete(&models.Department{}, dept.ID).Error; err != nil {
+		return nil, err
+	}
+	return ids, audit.Deleted(tx, models.AuditEntityDepartment, dept.ID, *dept)
+}
+
+// reorgReason 调动原因：操作说明加上填写的原因
+func reorgReason(action, reason string) string {
+	if reason == "" {
+		return action
+	}
+	return action + ": " + reason
+}
+
+// assignedTo 员工是否在该部分的名单中
+func assignedTo(part models.SplitPart, employeeID uint) bool {
+	for _, id := range part.EmployeeIDs {
+		if id == employeeID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+// service/reorg_test.go
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+)
+
+// 合并后原部门不再有主管，离职员工的档案也转入目标部门
+func TestMergeDepartmentMovesEveryEmployee(t *testing.T) {
+	source, target := testDepartment(t), testDepartment(t)
+	manager := testEmployee(t, int(models.StatusActive), &source.ID)
+	resigned := testEmployee(t, int(models.StatusResigned), &source.ID)
+
+	testTx(t, func(tx *gorm.DB) {
+		if err := tx.Model(&models.Department{}).Where("id = ?", source.ID).Update("manager_id", manager.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := MergeDepartment(tx, source.ID, target.ID, 0, "测试", time.Now())
+		if err != nil {
+			t.Fatalf("合并失败: %v", err)
+		}
+		if len(result.Transfers) != 1 || len(result.Moved) != 1 || result.Moved[0] != resigned.ID {
+			t.Errorf("调动 %v、直接转入 %v，期望 1 条调动并直接转入员工 #%d", result.Transfers, result.Moved, resigned.ID)
+		}
+
+		var archived models.Department
+		if err := tx.Unscoped().First(&archived, source.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if !archived.DeletedAt.Valid || archived.ManagerID != 0 {
+			t.Errorf("原部门 deleted_at=%v manager_id=%d，期望已归档且主管已清空", archived.DeletedAt, archived.ManagerID)
+		}
+		var merged models.Department
+		if err := tx.First(&merged, target.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if merged.ManagerID != manager.ID {
+			t.Errorf("目标部门主管 = %d，期望 %d", merged.ManagerID, manager.ID)
+		}
+
+		var count int64
+		if err := tx.Unscoped().Model(&models.Employee{}).Where("department_id = ?", source.ID).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("仍有 %d 名员工属于已归档的部门", count)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+// service/status.go
+package service
+
+import (
+	"fmt"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+)
+
+// StatusTransitionError 员工状态变更不在变更表中，或只能通过调动审批生效
+type StatusTransitionError struct {
+	From int
+	To   int
+	Via  int // 需通过的调动类型，0 表示不允许该变更
+}
+
+func (e *StatusTransitionError) Error() string {
+	from, to := models.GetStatusText(e.From), models.GetStatusText(e.To)
+	switch {
+	case e.From == e.To:
+		return fmt.Sprintf("员工当前已是「%s」状态", from)
+	case e.Via != 0:
+		return fmt.Sprintf("员工状态从「%s」变更为「%s」需提交「%s」申请，审批通过后生效",
+			from, to, models.GetTransferTypeText(e.Via))
+	}
+	return fmt.Sprintf("员工状态不能从「%s」变更为「%s」", from, to)
+}
+
+// CheckStatusChange 校验直接修改员工档案时的状态变更
+func CheckStatusChange(from, to int) error {
+	if from == to {
+		return nil
+	}
+	transition, ok := models.FindStatusTransition(from, to)
+	if !ok {
+		return &StatusTransitionError{From: from, To: to}
+	}
+	if transition.Via != 0 {
+		return &StatusTransitionError{From: from, To: to, Via: transition.Via}
+	}
+	return nil
+}
+
+// TransferTargetStatus 调动生效后员工的状态，不改变员工状态的调动 (部门、职位调动) 返回 0
+func TransferTargetStatus(transfer *models.Transfer) int {
+	switch transfer.Type {
+	case models.TransferTypeRetirement:
+		return int(models.StatusRetired)
+	case models.TransferTypeResignation:
+		return int(models.StatusResigned)
+	case models.TransferTypeRehire:
+		return int(models.StatusRehired)
+	case models.TransferTypeConfirmation:
+		return int(models.StatusActive)
+	case models.TransferTypePartTime:
+		return int(models.StatusPartTime)
+	}
+	return 0
+}
+
+// IsInactiveStatus 员工是否已离职或退休，这类员工只能办理返聘
+func IsInactiveStatus(status int) bool {
+	return status == int(models.StatusResigned) || status == int(models.StatusRetired)
+}
+
+// IsStatusTransfer 是否为改变员工状态的调动 (离退休、离职、返聘、转正、转兼职)
+func IsStatusTransfer(transferType int) bool {
+	return TransferTargetStatus(&models.Transfer{Type: transferType}) != 0
+}
+
+// CheckTransferStatus 校验调动引起的员工状态变更：变更表中该变更必须通过此类型的调动生效，
+// 例如「试用转正」只适用于试用期员工，兼职恢复全职直接修改员工档案即可。
+// 反向调动 (撤销) 恢复原状态，不受变更表限制。
+func CheckTransferStatus(employee *models.Employee, transfer *models.Transfer) error {
+	to := TransferTargetStatus(transfer)
+	if to == 0 || transfer.RevertOfID != nil {
+		return nil
+	}
+	if employee.Status == to {
+		return &StatusTransitionError{From: employee.Status, To: to}
+	}
+	transition, ok := models.FindStatusTransition(employee.Status, to)
+	if !ok || transition.Via != transfer.Type {
+		return &StatusTransitionError{From: employee.Status, To: to}
+	}
+	return nil
+}
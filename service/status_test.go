@@ -0,0 +1,95 @@
+// service/status_test.go
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+)
+
+// 变更表中需审批的变更，其调动类型生效后的状态必须就是变更的目标状态
+func TestStatusTransitionsMatchTransferTypes(t *testing.T) {
+	for from := int(models.StatusActive); from <= int(models.StatusRetired); from++ {
+		for _, transition := range models.NextStatuses(from) {
+			if transition.Via == 0 {
+				continue
+			}
+			if got := TransferTargetStatus(&models.Transfer{Type: transition.Via}); got != int(transition.To) {
+				t.Errorf("%s -> %s 经由「%s」，该调动生效后的状态为 %d",
+					models.GetStatusText(from), models.GetStatusText(int(transition.To)),
+					models.GetTransferTypeText(transition.Via), got)
+			}
+		}
+	}
+}
+
+func TestCheckStatusChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    int
+		to      int
+		wantErr bool
+		wantVia int
+	}{
+		{"状态不变", int(models.StatusActive), int(models.StatusActive), false, 0},
+		{"兼职恢复全职", int(models.StatusPartTime), int(models.StatusActive), false, 0},
+		{"返聘转在职", int(models.StatusRehired), int(models.StatusActive), false, 0},
+		{"转正需审批", int(models.StatusProbation), int(models.StatusActive), true, models.TransferTypeConfirmation},
+		{"离职需审批", int(models.StatusActive), int(models.StatusResigned), true, models.TransferTypeResignation},
+		{"不在变更表中", int(models.StatusResigned), int(models.StatusActive), true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckStatusChange(tt.from, tt.to)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("期望允许，得到错误: %v", err)
+				}
+				return
+			}
+			var serr *StatusTransitionError
+			if !errors.As(err, &serr) {
+				t.Fatalf("期望 *StatusTransitionError，得到 %v", err)
+			}
+			if serr.Via != tt.wantVia {
+				t.Errorf("Via = %d，期望 %d", serr.Via, tt.wantVia)
+			}
+		})
+	}
+}
+
+func TestCheckTransferStatus(t *testing.T) {
+	revertOf := uint(1)
+	tests := []struct {
+		name     string
+		status   models.EmployeeStatus
+		transfer models.Transfer
+		wantErr  bool
+	}{
+		{"试用转正", models.StatusProbation, models.Transfer{Type: models.TransferTypeConfirmation}, false},
+		{"在职转兼职", models.StatusActive, models.Transfer{Type: models.TransferTypePartTime}, false},
+		{"离职返聘", models.StatusResigned, models.Transfer{Type: models.TransferTypeRehire}, false},
+		{"退休返聘", models.StatusRetired, models.Transfer{Type: models.TransferTypeRehire}, false},
+		{"部门调动不改变状态", models.StatusProbation, models.Transfer{Type: models.TransferTypeDepartment}, false},
+		{"撤销不受变更表限制", models.StatusActive, models.Transfer{Type: models.TransferTypePartTime, RevertOfID: &revertOf}, false},
+		{"兼职不能办理转正", models.StatusPartTime, models.Transfer{Type: models.TransferTypeConfirmation}, true},
+		{"返聘员工不能办理转正", models.StatusRehired, models.Transfer{Type: models.TransferTypeConfirmation}, true},
+		{"试用期不能退休", models.StatusProbation, models.Transfer{Type: models.TransferTypeRetirement}, true},
+		{"在职不能返聘", models.StatusActive, models.Transfer{Type: models.TransferTypeRehire}, true},
+		{"已是兼职", models.StatusPartTime, models.Transfer{Type: models.TransferTypePartTime}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			employee := &models.Employee{Status: int(tt.status)}
+			err := CheckTransferStatus(employee, &tt.transfer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v，期望出错 %v", err, tt.wantErr)
+			}
+			var serr *StatusTransitionError
+			if err != nil && !errors.As(err, &serr) {
+				t.Fatalf("期望 *StatusTransitionError，得到 %T", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// service/transfer.go
+package service
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/audit"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
+	"gorm.io/gorm"
+)
+
+// runMu 保证同一进程内到期调动的处理不会并发执行（定时任务与手动触发）
+var runMu sync.Mutex
+
+// IsDue 调动是否已到生效日期
+func IsDue(transfer *models.Transfer, now time.Time) bool {
+	date, err := utils.ParseDate(utils.DateOnly(transfer.TransferDate))
+	if err != nil {
+		return false
+	}
+	return !date.After(now)
+}
+
+// ApplyTransfer 将调动内容写入员工档案，需在事务中调用。
+// 同时在调动记录上补全生效前的部门和前后的员工状态，撤销时据此恢复。
+func ApplyTransfer(tx *gorm.DB, transfer *models.Transfer) error {
+	var employee models.Employee
+	if err := tx.First(&employee, transfer.EmployeeID).Error; err != nil {
+		return fmt.Errorf("员工不存在")
+	}
+	before := employee
+
+	// 员工状态可能在审批后被修改，生效时再次校验状态变更
+	if err := CheckTransferStatus(&employee, transfer); err != nil {
+		return err
+	}
+
+	// 反向调动按原调动记录的值完整恢复，包括空值
+	restore := transfer.RevertOfID != nil
+	fromStatus := employee.Status
+
+	// 根据调动类型更新员工信息
+	switch transfer.Type {
+	case models.TransferTypeDepartment:
+		// 部门调动：调出部门必须与员工当前部门一致，防止覆盖期间的其他修改
+		if transfer.FromDeptID == nil {
+			transfer.FromDeptID = employee.DepartmentID
+		} else if !sameDept(transfer.FromDeptID, employee.DepartmentID) {
+			return fmt.Errorf("员工当前部门已变更，与申请中的调出部门不一致")
+		}
+		if transfer.ToDeptID == nil {
+			if !restore {
+				return fmt.Errorf("目标部门未设置")
+			}
+			employee.DepartmentID = nil // 恢复为无部门
+			break
+		}
+		var newDept models.Department
+		if err := tx.First(&newDept, *transfer.ToDeptID).Error; err != nil {
+			return fmt.Errorf("目标部门不存在")
+		}
+		employee.DepartmentID = &newDept.ID // 更新部门
+	case models.TransferTypePosition:
+		// 职位调动：原职位/职务必须与员工当前信息一致，防止覆盖期间的其他修改
+		if transfer.FromPosition != employee.Position || transfer.FromJobTitle != employee.JobTitle {
+			return fmt.Errorf("员工当前职位/职务已变更，与申请中的原职位不一致")
+		}
+		if restore || transfer.ToPosition != "" {
+			employee.Position = transfer.ToPosition
+		}
+		if restore || transfer.ToJobTitle != "" {
+			employee.JobTitle = transfer.ToJobTitle
+		}
+	case models.TransferTypeRetirement, models.TransferTypeResignation, models.TransferTypeRehire,
+		models.TransferTypeConfirmation, models.TransferTypePartTime:
+		// 离退休、离职、返聘、转正、转兼职：更新员工状态，撤销时恢复为原状态
+		if restore {
+			employee.Status = transfer.ToStatus
+		} else {
+			employee.Status = TransferTargetStatus(transfer)
+		}
+	}
+
+	// 保存员工变更，按读取时的版本号更新，防止覆盖并发的修改
+	if err := UpdateVersioned(tx, &models.Employee{}, employee.ID, employee.Version, map[string]interface{}{
+		"department_id": employee.DepartmentID,
+		"position":      employee.Position,
+		"job_title":     employee.JobTitle,
+		"status":        employee.Status,
+	}); err != nil {
+		return err
+	}
+	employee.Version++
+	if err := audit.TransferApplied(tx, transfer.ID, employee.ID, before, employee); err != nil {
+		return err
+	}
+
+	transfer.FromStatus = fromStatus
+	transfer.ToStatus = employee.Status
+	return tx.Model(&models.Transfer{}).Where("id = ?", transfer.ID).
+		Updates(map[string]interface{}{
+			"from_dept_id": transfer.FromDeptID,
+			"from_status":  transfer.FromStatus,
+			"to_status":    transfer.ToStatus,
+		}).Error
+}
+
+// CompleteTransfer 将已批准的调动标记为已完成并写入员工档案，需在事务中调用。
+// 通过带状态条件的更新实现幂等：已被其他流程完成的调动返回 false，不会重复生效。
+func CompleteTransfer(tx *gorm.DB, transfer *models.Transfer, now time.Time) (bool, error) {
+	before := *transfer
+	result := tx.Model(&models.Transfer{}).
+		Where("id = ? AND status = ?", transfer.ID, models.TransferStatusApproved).
+		Updates(map[string]interface{}{
+			"status":       models.TransferStatusCompleted,
+			"completed_at": now,
+			"version":      gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	if err := ApplyTransfer(tx, transfer); err != nil {
+		return false, err
+	}
+	transfer.Status = models.TransferStatusCompleted
+	transfer.CompletedAt = &now
+	transfer.Version++
+	return true, audit.Updated(tx, models.AuditEntityTransfer, transfer.ID, before, *transfer)
+}
+
+// RunResult 到期调动处理结果
+type RunResult struct {
+	Completed []uint          `json:"completed"` // 本次生效的调动ID
+	Failed    map[uint]string `json:"failed"`    // 处理失败的调动ID及原因
+	RunAt     time.Time       `json:"run_at"`
+}
+
+// RunDueTransfers 处理所有生效日期已到的已批准调动。
+// 每条调动在独立事务中完成"状态变更 + 员工档案更新"，中途崩溃不会出现只改一半的数据，
+// 重启后重新执行即可继续处理剩余记录。
+func RunDueTransfers(db *gorm.DB, now time.Time) (RunResult, error) {
+	runMu.Lock()
+	defer runMu.Unlock()
+
+	result := RunResult{Completed: []uint{}, Failed: map[uint]string{}, RunAt: now}
+
+	var due []models.Transfer
+	if err := db.Where("status = ? AND transfer_date <= ?", models.TransferStatusApproved, utils.FormatDate(now)).
+		Order("transfer_date, id").Find(&due).Error; err != nil {
+		return result, fmt.Errorf("查询到期调动失败: %v", err)
+	}
+
+	for i := range due {
+		transfer := &due[i]
+		var completed bool
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			completed, err = CompleteTransfer(tx, transfer, now)
+			return err
+		})
+		if err != nil {
+			log.Printf("⚠️ 调动 #%d 生效失败: %v", transfer.ID, err)
+			result.Failed[transfer.ID] = err.Error()
+			continue
+		}
+		if completed {
+			result.Completed = append(result.Completed, transfer.ID)
+		}
+	}
+
+	if len(result.Completed) > 0 || len(result.Failed) > 0 {
+		log.Printf("到期调动处理完成: 生效 %d 条，失败 %d 条", len(result.Completed), len(result.Failed))
+	}
+	return result, nil
+}
@@ -0,0 +1,43 @@
+// service/transfer_test.go
+package service
+
+import (
+	"testing"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+)
+
+// 审批后员工部门被修改过时，到期生效不能覆盖这次修改
+func TestApplyTransferDepartmentChanged(t *testing.T) {
+	from, to, other := testDepartment(t), testDepartment(t), testDepartment(t)
+	employee := testEmployee(t, int(models.StatusActive), &from.ID)
+
+	testTx(t, func(tx *gorm.DB) {
+		transfer := &models.Transfer{EmployeeID: employee.ID, Type: models.TransferTypeDepartment, TransferDate: "2024-06-01",
+			FromDeptID: &from.ID, ToDeptID: &to.ID, Status: models.TransferStatusApproved, Version: 1}
+		if err := tx.Create(transfer).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Model(&models.Employee{}).Where("id = ?", employee.ID).Update("department_id", other.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ApplyTransfer(tx, transfer); err == nil {
+			t.Fatal("员工部门已变更，期望生效失败")
+		}
+		var current models.Employee
+		if err := tx.First(&current, employee.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if current.DepartmentID == nil || *current.DepartmentID != other.ID {
+			t.Errorf("员工部门 = %v，期望保持为 %d", current.DepartmentID, other.ID)
+		}
+
+		// 调出部门与当前部门一致时正常生效
+		transfer.FromDeptID = &other.ID
+		if err := ApplyTransfer(tx, transfer); err != nil {
+			t.Fatalf("生效失败: %v", err)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+// service/validate.go
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FieldError 一个字段的校验错误，Field 为请求中的字段名
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError 调动申请校验未通过，包含全部字段错误
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, "；")
+}
+
+func (e *ValidationError) add(field, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// transferCategory 调动的类别，同一员工同一类别只能有一条未生效的申请
+func transferCategory(transferType int) string {
+	switch transferType {
+	case models.TransferTypeDepartment:
+		return "department"
+	case models.TransferTypePosition:
+		return "position"
+	}
+	return "status" // 改变员工状态的调动
+}
+
+// ValidateTransfer 按调动类型校验新提交的调动申请与员工当前信息是否一致，需在创建申请的事务中调用：
+//   - 日期：生效日期格式正确且不早于入职日期；离职的最后工作日、返聘的合同期限合理
+//   - 部门：调出部门为员工当前部门；部门调动的调入部门存在且与当前部门不同
+//   - 状态：离职、退休员工不能调动部门或职位；状态类调动符合状态变更表
+//   - 重复：员工没有同类别的未生效申请 (待审批或已批准)，检查前锁定员工记录
+//
+// 校验通过时各日期字段规范为 YYYY-MM-DD；未通过时返回 *ValidationError。
+func ValidateTransfer(tx *gorm.DB, employee *models.Employee, transfer *models.Transfer) error {
+	verr := &ValidationError{}
+
+	// 日期
+	arrival, arrivalErr := utils.ParseDate(utils.DateOnly(employee.ArrivalDate))
+	date, err := utils.ParseDate(transfer.TransferDate)
+	if err != nil {
+		verr.add("transfer_date", "生效日期格式错误，应为 YYYY-MM-DD")
+	} else {
+		transfer.TransferDate = utils.FormatDate(date)
+		if arrivalErr == nil && date.Before(arrival) {
+			verr.add("transfer_date", "生效日期不能早于员工入职日期 %s", utils.FormatDate(arrival))
+		}
+	}
+
+	// 调出部门
+	if transfer.FromDeptID != nil && !sameDept(transfer.FromDeptID, employee.DepartmentID) {
+		verr.add("from_dept_id", "调出部门与员工当前所在部门不一致")
+	}
+
+	// 离职、退休的员工只能办理返聘
+	inactive := IsInactiveStatus(employee.Status)
+	typeText := models.GetTransferTypeText(transfer.Type)
+
+	switch transfer.Type {
+	case models.TransferTypeDepartment:
+		if inactive {
+			verr.add("employee_id", "员工已%s，不能办理%s", models.GetStatusText(employee.Status), typeText)
+		}
+		if transfer.ToDeptID == nil {
+			verr.add("to_dept_id", "部门调动需填写调入部门")
+			break
+		}
+		var toDept models.Department
+		if err := tx.First(&toDept, *transfer.ToDeptID).Error; err != nil {
+			verr.add("to_dept_id", "调入部门不存在或已删除")
+		} else if sameDept(transfer.ToDeptID, employee.DepartmentID) {
+			verr.add("to_dept_id", "调入部门与员工当前所在部门「%s」相同", toDept.Name)
+		}
+	case models.TransferTypePosition:
+		if inactive {
+			verr.add("employee_id", "员工已%s，不能办理%s", models.GetStatusText(employee.Status), typeText)
+		}
+		if transfer.FromPosition != employee.Position || transfer.FromJobTitle != employee.JobTitle {
+			verr.add("from_position", "原职位/职务与员工当前信息不一致")
+		}
+		switch {
+		case transfer.ToPosition == "" && transfer.ToJobTitle == "":
+			verr.add("to_position", "职位调动需填写新职位或新职务")
+		case (transfer.ToPosition == "" || transfer.ToPosition == employee.Position) &&
+			(transfer.ToJobTitle == "" || transfer.ToJobTitle == employee.JobTitle):
+			verr.add("to_position", "新职位/职务与员工当前信息相同")
+		}
+	case models.TransferTypeResignation:
+		if day, ok := validateDate(verr, "last_working_day", "离职申请需填写最后工作日", transfer.LastWorkingDay); ok {
+			if err == nil && day.After(date) {
+				verr.add("last_working_day", "最后工作日不能晚于离职生效日期")
+			}
+			if arrivalErr == nil && day.Before(arrival) {
+				verr.add("last_working_day", "最后工作日不能早于员工入职日期 %s", utils.FormatDate(arrival))
+			}
+		}
+	case models.TransferTypeRehire:
+		start, startOK := validateDate(verr, "contract_start_date", "返聘申请需填写合同开始日期", transfer.ContractStartDate)
+		end, endOK := validateDate(verr, "contract_end_date", "返聘申请需填写合同结束日期", transfer.ContractEndDate)
+		if startOK && endOK && !end.After(start) {
+			verr.add("contract_end_date", "合同结束日期必须晚于开始日期")
+		}
+	}
+
+	// 状态类调动需符合状态变更表
+	if err := CheckTransferStatus(employee, transfer); err != nil {
+		verr.add("type", "%s", err.Error())
+	}
+
+	// 同一员工同一类别只能有一条未生效的申请。
+	// 先锁定员工记录 (SELECT ... FOR UPDATE)，并发提交的申请依次检查，不会各自通过后重复创建；
+	// SQLite 不支持行锁，由事务开始时获取的写锁保证依次执行
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		First(&models.Employee{}, employee.ID).Error; err != nil {
+		return err
+	}
+	var open []models.Transfer
+	if err := tx.Where("employee_id = ? AND status IN ?", employee.ID,
+		[]int{models.TransferStatusPending, models.TransferStatusApproved}).
+		Order("id").Find(&open).Error; err != nil {
+		return err
+	}
+	for _, t := range open {
+		if transferCategory(t.Type) == transferCategory(transfer.Type) {
+			verr.add("employee_id", "员工已有未生效的「%s」申请 (#%d)，请先处理", models.GetTransferTypeText(t.Type), t.ID)
+			break
+		}
+	}
+
+	if len(verr.Errors) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// validateDate 校验必填的日期字段，通过时规范为 YYYY-MM-DD
+func validateDate(verr *ValidationError, field, required string, value *string) (time.Time, bool) {
+	if value == nil || *value == "" {
+		verr.add(field, "%s", required)
+		return time.Time{}, false
+	}
+	t, err := utils.ParseDate(*value)
+	if err != nil {
+		verr.add(field, "%s，格式为 YYYY-MM-DD", required)
+		return time.Time{}, false
+	}
+	*value = utils.FormatDate(t)
+	return t, true
+}
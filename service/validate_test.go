@@ -0,0 +1,213 @@
+// service/validate_test.go
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+	"gorm.io/gorm"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestValidateTransfer(t *testing.T) {
+	from := testDepartment(t)
+	to := testDepartment(t)
+	missing := uint(999999)
+
+	tests := []struct {
+		name       string
+		status     models.EmployeeStatus
+		transfer   func(e *models.Employee) models.Transfer
+		wantFields []string
+	}{
+		{
+			name:   "部门调动通过",
+			status: models.StatusActive,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypeDepartment, TransferDate: "2024-06-01", FromDeptID: e.DepartmentID, ToDeptID: &to.ID}
+			},
+		},
+		{
+			name:   "生效日期格式错误",
+			status: models.StatusActive,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypeDepartment, TransferDate: "2024/06/01", ToDeptID: &to.ID}
+			},
+			wantFields: []string{"transfer_date"},
+		},
+		{
+			name:   "生效日期带多余内容",
+			status: models.StatusActive,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypeDepartment, TransferDate: "2024-06-01xyz", ToDeptID: &to.ID}
+			},
+			wantFields: []string{"transfer_date"},
+		},
+		{
+			name:   "生效日期早于入职日期",
+			status: models.StatusActive,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypeDepartment, TransferDate: "2019-12-31", ToDeptID: &to.ID}
+			},
+			wantFields: []string{"transfer_date"},
+		},
+		{
+			name:   "调出部门不一致且调入部门不存在",
+			status: models.StatusActive,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypeDepartment, TransferDate: "2024-06-01", FromDeptID: &to.ID, ToDeptID: &missing}
+			},
+			wantFields: []string{"from_dept_id", "to_dept_id"},
+		},
+		{
+			name:   "调入部门与当前部门相同",
+			status: models.StatusActive,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypeDepartment, TransferDate: "2024-06-01", ToDeptID: &from.ID}
+			},
+			wantFields: []string{"to_dept_id"},
+		},
+		{
+			name:   "离职员工不能调动部门",
+			status: models.StatusResigned,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypeDepartment, TransferDate: "2024-06-01", ToDeptID: &to.ID}
+			},
+			wantFields: []string{"employee_id"},
+		},
+		{
+			name:   "职位调动未填写新职位",
+			status: models.StatusActive,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypePosition, TransferDate: "2024-06-01", FromPosition: e.Position, FromJobTitle: e.JobTitle}
+			},
+			wantFields: []string{"to_position"},
+		},
+		{
+			name:   "职位调动原职位不一致",
+			status: models.StatusActive,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypePosition, TransferDate: "2024-06-01", FromPosition: "其他", ToPosition: "经理"}
+			},
+			wantFields: []string{"from_position"},
+		},
+		{
+			name:   "离职最后工作日晚于生效日期",
+			status: models.StatusActive,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypeResignation, TransferDate: "2024-06-01", LastWorkingDay: strPtr("2024-06-02")}
+			},
+			wantFields: []string{"last_working_day"},
+		},
+		{
+			name:   "离职未填写最后工作日",
+			status: models.StatusActive,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypeResignation, TransferDate: "2024-06-01"}
+			},
+			wantFields: []string{"last_working_day"},
+		},
+		{
+			name:   "返聘合同结束日期早于开始日期",
+			status: models.StatusRetired,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypeRehire, TransferDate: "2024-06-01",
+					ContractStartDate: strPtr("2024-06-01"), ContractEndDate: strPtr("2024-05-31")}
+			},
+			wantFields: []string{"contract_end_date"},
+		},
+		{
+			name:   "状态变更不在变更表中",
+			status: models.StatusProbation,
+			transfer: func(e *models.Employee) models.Transfer {
+				return models.Transfer{Type: models.TransferTypePartTime, TransferDate: "2024-06-01"}
+			},
+			wantFields: []string{"type"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			employee := testEmployee(t, int(tt.status), &from.ID)
+			transfer := tt.transfer(employee)
+			testTx(t, func(tx *gorm.DB) {
+				assertFieldErrors(t, ValidateTransfer(tx, employee, &transfer), tt.wantFields)
+			})
+		})
+	}
+}
+
+// 同一员工同一类别只能有一条未生效的申请，不同类别互不影响
+func TestValidateTransferOpenRequest(t *testing.T) {
+	from := testDepartment(t)
+	to := testDepartment(t)
+	employee := testEmployee(t, int(models.StatusActive), &from.ID)
+
+	testTx(t, func(tx *gorm.DB) {
+		open := models.Transfer{EmployeeID: employee.ID, Type: models.TransferTypeDepartment, TransferDate: "2024-06-01",
+			FromDeptID: &from.ID, ToDeptID: &to.ID, Status: models.TransferStatusPending}
+		if err := tx.Create(&open).Error; err != nil {
+			t.Fatalf("创建调动失败: %v", err)
+		}
+
+		again := models.Transfer{Type: models.TransferTypeDepartment, TransferDate: "2024-07-01", ToDeptID: &to.ID}
+		assertFieldErrors(t, ValidateTransfer(tx, employee, &again), []string{"employee_id"})
+
+		position := models.Transfer{Type: models.TransferTypePosition, TransferDate: "2024-07-01",
+			FromPosition: employee.Position, FromJobTitle: employee.JobTitle, ToPosition: "高级工程师"}
+		assertFieldErrors(t, ValidateTransfer(tx, employee, &position), nil)
+	})
+}
+
+// 同一事务中先后提交同一员工同类别的两条申请 (如批量提交)，第二条应被拒绝
+func TestValidateTransferSameBatch(t *testing.T) {
+	from := testDepartment(t)
+	to := testDepartment(t)
+	other := testDepartment(t)
+	employee := testEmployee(t, int(models.StatusActive), &from.ID)
+
+	testTx(t, func(tx *gorm.DB) {
+		batch := []models.Transfer{
+			{EmployeeID: employee.ID, Type: models.TransferTypeDepartment, TransferDate: "2024-06-01", ToDeptID: &to.ID},
+			{EmployeeID: employee.ID, Type: models.TransferTypeDepartment, TransferDate: "2024-07-01", ToDeptID: &other.ID},
+		}
+		var errs []error
+		for i := range batch {
+			err := ValidateTransfer(tx, employee, &batch[i])
+			if err == nil {
+				batch[i].Status, batch[i].Version = models.TransferStatusPending, 1
+				if err := tx.Create(&batch[i]).Error; err != nil {
+					t.Fatalf("创建调动失败: %v", err)
+				}
+			}
+			errs = append(errs, err)
+		}
+		assertFieldErrors(t, errs[0], nil)
+		assertFieldErrors(t, errs[1], []string{"employee_id"})
+	})
+}
+
+// assertFieldErrors 校验 ValidateTransfer 返回的出错字段，want 为空表示应校验通过
+func assertFieldErrors(t *testing.T, err error, want []string) {
+	t.Helper()
+	if len(want) == 0 {
+		if err != nil {
+			t.Fatalf("期望校验通过，得到: %v", err)
+		}
+		return
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("期望 *ValidationError，得到 %v", err)
+	}
+	var got []string
+	for _, fe := range verr.Errors {
+		got = append(got, fe.Field)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("出错字段 = %v，期望 %v (%v)", got, want, verr)
+	}
+}
@@ -0,0 +1,31 @@
+// service/version.go
+package service
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict 记录在读取之后已被其他操作修改 (版本号不一致)
+var ErrVersionConflict = errors.New("记录已被其他人修改，请刷新后重试")
+
+// UpdateVersioned 按版本号条件更新一条记录 (员工、部门或调动)，同时把版本号加一，需在事务中调用。
+// version 为读取记录时的版本号，记录在此之后被修改过 (或已不存在) 时不做任何修改并返回 ErrVersionConflict。
+// values 可以为空，此时只增加版本号，用于在事务中"占用"记录，使并发的同类操作失败。
+func UpdateVersioned(tx *gorm.DB, model interface{}, id, version uint, values map[string]interface{}) error {
+	updates := make(map[string]interface{}, len(values)+1)
+	for k, v := range values {
+		updates[k] = v
+	}
+	updates["version"] = version + 1
+
+	result := tx.Model(model).Where("id = ? AND version = ?", id, version).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
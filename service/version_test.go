@@ -0,0 +1,39 @@
+// service/version_test.go
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/database"
+	"github.com/YEDINGHAO/Personnel-Transfer-Management-System/models"
+)
+
+func TestUpdateVersioned(t *testing.T) {
+	db := database.GetDB()
+	employee := testEmployee(t, int(models.StatusActive), nil)
+	read := employee.Version
+
+	if err := UpdateVersioned(db, &models.Employee{}, employee.ID, read, map[string]interface{}{"phone": "13800000000"}); err != nil {
+		t.Fatalf("按当前版本号更新失败: %v", err)
+	}
+
+	// 以旧版本号再次更新：不修改任何字段
+	err := UpdateVersioned(db, &models.Employee{}, employee.ID, read, map[string]interface{}{"phone": "13900000000"})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("期望 ErrVersionConflict，得到 %v", err)
+	}
+
+	var current models.Employee
+	if err := db.First(&current, employee.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if current.Version != read+1 || current.Phone != "13800000000" {
+		t.Errorf("version = %d, phone = %q，期望 %d, 13800000000", current.Version, current.Phone, read+1)
+	}
+
+	// 记录不存在时同样视为冲突
+	if err := UpdateVersioned(db, &models.Employee{}, 999999, 1, nil); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("记录不存在: 期望 ErrVersionConflict，得到 %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+// utils/date.go
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout 系统统一使用的日期格式
+const DateLayout = "2006-01-02"
+
+// ParseDate 严格按 YYYY-MM-DD 解析日期字符串，用于请求参数和导入数据。
+// 从数据库读出的 date 列可能带有时间部分，需先经 DateOnly 处理。
+func ParseDate(s string) (time.Time, error) {
+	t, err := time.ParseInLocation(DateLayout, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("日期格式错误，应为 YYYY-MM-DD: %s", s)
+	}
+	return t, nil
+}
+
+// DateOnly 去掉驱动读出的时间部分（如 2024-06-01T00:00:00Z），只保留 YYYY-MM-DD
+func DateOnly(s string) string {
+	if len(s) > len(DateLayout) {
+		return s[:len(DateLayout)]
+	}
+	return s
+}
+
+// FormatDate 格式化为 YYYY-MM-DD
+func FormatDate(t time.Time) string {
+	return t.Format(DateLayout)
+}
+
+// Today 当天日期 (YYYY-MM-DD)
+func Today() string {
+	return FormatDate(time.Now())
+}
@@ -2,12 +2,28 @@
 package utils
 
 import (
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
-var jwtSecret = []byte("hrms-secret-key") // 生产环境请从配置文件读取
+var (
+	jwtSecret []byte // 由 Setup 从配置注入
+	jwtExpire = 24 * time.Hour
+	jwtIssuer = "hrms-api"
+)
+
+// Setup 设置签名密钥、有效期和签发者
+func Setup(secret string, expire time.Duration, issuer string) {
+	jwtSecret = []byte(secret)
+	if expire > 0 {
+		jwtExpire = expire
+	}
+	if issuer != "" {
+		jwtIssuer = issuer
+	}
+}
 
 // Claims JWT声明
 type Claims struct {
@@ -19,8 +35,12 @@ type Claims struct {
 
 // GenerateToken 生成JWT令牌
 func GenerateToken(userID uint, username string, role int) (string, error) {
+	if len(jwtSecret) == 0 {
+		return "", errors.New("未配置令牌签名密钥")
+	}
+
 	now := time.Now()
-	expireTime := now.Add(24 * time.Hour)
+	expireTime := now.Add(jwtExpire)
 
 	claims := Claims{
 		UserID:   userID,
@@ -30,7 +50,7 @@ func GenerateToken(userID uint, username string, role int) (string, error) {
 			ExpiresAt: jwt.NewNumericDate(expireTime),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    "hrms-api",
+			Issuer:    jwtIssuer,
 		},
 	}
 
@@ -41,6 +61,10 @@ func GenerateToken(userID uint, username string, role int) (string, error) {
 // ParseToken 解析JWT令牌
 func ParseToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		// 只接受 HMAC 签名，防止算法替换攻击
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("不支持的签名算法")
+		}
 		return jwtSecret, nil
 	})
 
@@ -49,8 +73,11 @@ func ParseToken(tokenString string) (*Claims, error) {
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		if !claims.VerifyIssuer(jwtIssuer, true) {
+			return nil, errors.New("令牌签发者不匹配")
+		}
 		return claims, nil
 	}
 
-	return nil, err
+	return nil, errors.New("无效的令牌")
 }